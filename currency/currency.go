@@ -1,13 +1,160 @@
 package currency
 
-import "fmt"
-
-// Format formats cent representation of currency into dollars with cents
-// Ex. 1 -> 0.01$
-// Ex 50 -> 0.50$
-// Ex 1155 -> 11.55$
-func Format(value int) string {
-	cents := value % 100
-	dollars := value / 100
-	return fmt.Sprintf("%d.%02.f$", dollars, float64(cents))
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultCode is the currency of wallets created without explicit currency
+const DefaultCode = "USD"
+
+// Currency describes ISO 4217 currency, amounts are stored in minor units,
+// Exponent is the number of minor unit digits (cents) in the major unit
+type Currency struct {
+	Code     string
+	Exponent int
+	Symbol   string
+}
+
+var currencies = map[string]Currency{
+	"USD": {Code: "USD", Exponent: 2, Symbol: "$"},
+	"EUR": {Code: "EUR", Exponent: 2, Symbol: "€"},
+	"GBP": {Code: "GBP", Exponent: 2, Symbol: "£"},
+	"CHF": {Code: "CHF", Exponent: 2, Symbol: "CHF"},
+	"RUB": {Code: "RUB", Exponent: 2, Symbol: "₽"},
+	"JPY": {Code: "JPY", Exponent: 0, Symbol: "¥"},
+	"KRW": {Code: "KRW", Exponent: 0, Symbol: "₩"},
+	"BHD": {Code: "BHD", Exponent: 3, Symbol: "BHD"},
+	"KWD": {Code: "KWD", Exponent: 3, Symbol: "KWD"},
+}
+
+// Lookup returns supported currency by its ISO 4217 code
+func Lookup(code string) (Currency, bool) {
+	c, ok := currencies[code]
+	return c, ok
+}
+
+// SymbolPosition is the placement of currency symbol relative to the number
+type SymbolPosition int
+
+const (
+	SymbolSuffix SymbolPosition = iota
+	SymbolPrefix
+)
+
+// Options control formatting of amounts
+type Options struct {
+	SymbolPosition SymbolPosition
+	// SymbolSpace separates symbol from the number with a space,
+	// alphabetic symbols (ex. CHF) placed before the number are always separated
+	SymbolSpace        bool
+	ThousandsSeparator string
+	DecimalSeparator   string
+}
+
+// DefaultOptions formats amounts as 1234.56$
+var DefaultOptions = Options{SymbolPosition: SymbolSuffix, DecimalSeparator: "."}
+
+// plain spaces are used instead of non-breaking ones to keep reports readable by any tool
+var locales = map[string]Options{
+	"en-US": {SymbolPosition: SymbolPrefix, ThousandsSeparator: ",", DecimalSeparator: "."},
+	"de-DE": {SymbolPosition: SymbolSuffix, SymbolSpace: true, ThousandsSeparator: ".", DecimalSeparator: ","},
+	"fr-FR": {SymbolPosition: SymbolSuffix, SymbolSpace: true, ThousandsSeparator: " ", DecimalSeparator: ","},
+}
+
+// Locale returns formatting options preset of the locale
+// Ex. 123456, EUR, en-US -> €1,234.56
+// Ex. 123456, EUR, de-DE -> 1.234,56 €
+// Ex. 123456, EUR, fr-FR -> 1 234,56 €
+func Locale(name string) (Options, bool) {
+	o, ok := locales[name]
+	return o, ok
+}
+
+// Format formats minor units representation of amount in the currency with code using DefaultOptions
+// Ex. 1, USD -> 0.01$
+// Ex 1155, USD -> 11.55$
+// Ex -150, USD -> -1.50$
+// Ex 1155, JPY -> 1155¥
+// Ex 1155, BHD -> 1.155BHD
+func Format(value int64, code string) string {
+	return FormatWith(value, code, DefaultOptions)
+}
+
+// FormatWith formats minor units representation of amount in the currency with code using options
+func FormatWith(value int64, code string, opts Options) string {
+	c, ok := Lookup(code)
+	if !ok {
+		c = Currency{Code: code, Exponent: 2, Symbol: code}
+	}
+
+	// unsigned absolute value doesn't overflow for math.MinInt64
+	abs := uint64(value)
+	if value < 0 {
+		abs = -abs
+	}
+
+	digits := strconv.FormatUint(abs, 10)
+	if len(digits) <= c.Exponent {
+		digits = strings.Repeat("0", c.Exponent-len(digits)+1) + digits
+	}
+
+	major, minor := digits[:len(digits)-c.Exponent], digits[len(digits)-c.Exponent:]
+
+	var b strings.Builder
+	if value < 0 {
+		b.WriteByte('-')
+	}
+
+	if opts.SymbolPosition == SymbolPrefix {
+		b.WriteString(c.Symbol)
+		if opts.SymbolSpace || isAlphabetic(c.Symbol) {
+			b.WriteByte(' ')
+		}
+	}
+
+	b.WriteString(groupThousands(major, opts.ThousandsSeparator))
+	if c.Exponent > 0 {
+		b.WriteString(opts.DecimalSeparator)
+		b.WriteString(minor)
+	}
+
+	if opts.SymbolPosition == SymbolSuffix {
+		if opts.SymbolSpace {
+			b.WriteByte(' ')
+		}
+		b.WriteString(c.Symbol)
+	}
+
+	return b.String()
+}
+
+func groupThousands(digits, sep string) string {
+	if sep == "" || len(digits) <= 3 {
+		return digits
+	}
+
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+
+	return b.String()
+}
+
+func isAlphabetic(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return s != ""
 }
@@ -2,15 +2,17 @@ package currency_test
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/justteddy/wallet/currency"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFormat(t *testing.T) {
 	cases := []struct {
-		cents    int
+		cents    int64
 		expected string
 	}{
 		{
@@ -61,7 +63,180 @@ func TestFormat(t *testing.T) {
 
 	for i, c := range cases {
 		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
-			assert.Equal(t, c.expected, currency.Format(c.cents))
+			assert.Equal(t, c.expected, currency.Format(c.cents, currency.DefaultCode))
 		})
 	}
 }
+
+func TestFormatExponent(t *testing.T) {
+	cases := []struct {
+		value    int64
+		code     string
+		expected string
+	}{
+		{
+			value:    1155,
+			code:     "EUR",
+			expected: "11.55€",
+		},
+		{
+			value:    1155,
+			code:     "JPY",
+			expected: "1155¥",
+		},
+		{
+			value:    0,
+			code:     "JPY",
+			expected: "0¥",
+		},
+		{
+			value:    1155,
+			code:     "BHD",
+			expected: "1.155BHD",
+		},
+		{
+			value:    5,
+			code:     "BHD",
+			expected: "0.005BHD",
+		},
+		{
+			value:    1155,
+			code:     "XXX",
+			expected: "11.55XXX",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.code+" "+c.expected, func(t *testing.T) {
+			assert.Equal(t, c.expected, currency.Format(c.value, c.code))
+		})
+	}
+}
+
+func TestFormatNegative(t *testing.T) {
+	cases := []struct {
+		value    int64
+		code     string
+		expected string
+	}{
+		{
+			value:    -150,
+			code:     "USD",
+			expected: "-1.50$",
+		},
+		{
+			value:    -1,
+			code:     "USD",
+			expected: "-0.01$",
+		},
+		{
+			value:    -1155,
+			code:     "JPY",
+			expected: "-1155¥",
+		},
+		{
+			value:    -5,
+			code:     "BHD",
+			expected: "-0.005BHD",
+		},
+		{
+			value:    math.MinInt64,
+			code:     "USD",
+			expected: "-92233720368547758.08$",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.code+" "+c.expected, func(t *testing.T) {
+			assert.Equal(t, c.expected, currency.Format(c.value, c.code))
+		})
+	}
+}
+
+func TestFormatWith(t *testing.T) {
+	cases := []struct {
+		value    int64
+		code     string
+		locale   string
+		expected string
+	}{
+		{
+			value:    123456,
+			code:     "USD",
+			locale:   "en-US",
+			expected: "$1,234.56",
+		},
+		{
+			value:    -123456,
+			code:     "USD",
+			locale:   "en-US",
+			expected: "-$1,234.56",
+		},
+		{
+			value:    123456,
+			code:     "CHF",
+			locale:   "en-US",
+			expected: "CHF 1,234.56",
+		},
+		{
+			value:    99,
+			code:     "EUR",
+			locale:   "en-US",
+			expected: "€0.99",
+		},
+		{
+			value:    123456789,
+			code:     "EUR",
+			locale:   "de-DE",
+			expected: "1.234.567,89 €",
+		},
+		{
+			value:    -150,
+			code:     "EUR",
+			locale:   "de-DE",
+			expected: "-1,50 €",
+		},
+		{
+			value:    1234567,
+			code:     "JPY",
+			locale:   "de-DE",
+			expected: "1.234.567 ¥",
+		},
+		{
+			value:    123456,
+			code:     "EUR",
+			locale:   "fr-FR",
+			expected: "1 234,56 €",
+		},
+		{
+			value:    100000,
+			code:     "EUR",
+			locale:   "fr-FR",
+			expected: "1 000,00 €",
+		},
+		{
+			value:    1234567,
+			code:     "BHD",
+			locale:   "fr-FR",
+			expected: "1 234,567 BHD",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.locale+" "+c.expected, func(t *testing.T) {
+			opts, ok := currency.Locale(c.locale)
+			require.True(t, ok)
+			assert.Equal(t, c.expected, currency.FormatWith(c.value, c.code, opts))
+		})
+	}
+
+	t.Run("custom options", func(t *testing.T) {
+		opts := currency.Options{SymbolPosition: currency.SymbolPrefix, ThousandsSeparator: "'", DecimalSeparator: "."}
+		assert.Equal(t, "£1'000'000.00", currency.FormatWith(100000000, "GBP", opts))
+	})
+
+	t.Run("unknown locale", func(t *testing.T) {
+		_, ok := currency.Locale("xx-XX")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,72 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrEmptyAmount         = errors.New("empty amount")
+	ErrNegativeAmount      = errors.New("amount must not be negative")
+	ErrMalformedAmount     = errors.New("amount must be a decimal number like 12.34")
+	ErrExcessPrecision     = errors.New("amount has too many decimal places")
+	ErrAmountOutOfRange    = errors.New("amount is out of range")
+)
+
+// ParseDecimal parses decimal representation of amount in the currency with code to minor units
+// Ex. "12.34", USD -> 1234
+// Ex. "12", USD -> 1200
+// Ex. "1155", JPY -> 1155
+// Ex. "1.155", BHD -> 1155
+func ParseDecimal(s string, code string) (int64, error) {
+	c, ok := Lookup(code)
+	if !ok {
+		return 0, fmt.Errorf("%w %s", ErrUnsupportedCurrency, code)
+	}
+
+	if s == "" {
+		return 0, ErrEmptyAmount
+	}
+	if s[0] == '-' {
+		return 0, ErrNegativeAmount
+	}
+
+	major, minor := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		major, minor = s[:i], s[i+1:]
+		if !isDigits(minor) {
+			return 0, fmt.Errorf("%w, got %q", ErrMalformedAmount, s)
+		}
+	}
+	if !isDigits(major) {
+		return 0, fmt.Errorf("%w, got %q", ErrMalformedAmount, s)
+	}
+	if len(minor) > c.Exponent {
+		if c.Exponent == 0 {
+			return 0, fmt.Errorf("%w, %s has no minor units", ErrExcessPrecision, c.Code)
+		}
+		return 0, fmt.Errorf("%w, %s allows at most %d", ErrExcessPrecision, c.Code, c.Exponent)
+	}
+
+	value, err := strconv.ParseInt(major+minor+strings.Repeat("0", c.Exponent-len(minor)), 10, 64)
+	if err != nil {
+		return 0, ErrAmountOutOfRange
+	}
+
+	return value, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package currency_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/justteddy/wallet/currency"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDecimal(t *testing.T) {
+	cases := []struct {
+		value    string
+		code     string
+		expected int64
+	}{
+		{value: "12.34", code: "USD", expected: 1234},
+		{value: "12.3", code: "USD", expected: 1230},
+		{value: "12", code: "USD", expected: 1200},
+		{value: "0.01", code: "USD", expected: 1},
+		{value: "0", code: "USD", expected: 0},
+		{value: "007.50", code: "EUR", expected: 750},
+		{value: "1155", code: "JPY", expected: 1155},
+		{value: "1.155", code: "BHD", expected: 1155},
+		{value: "92233720368547758.07", code: "USD", expected: math.MaxInt64},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.value+" "+tc.code, func(t *testing.T) {
+			actual, err := currency.ParseDecimal(tc.value, tc.code)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestParseDecimalErrors(t *testing.T) {
+	cases := []struct {
+		value string
+		code  string
+		err   error
+		msg   string
+	}{
+		{value: "1.00", code: "XXX", err: currency.ErrUnsupportedCurrency, msg: "unsupported currency XXX"},
+		{value: "", code: "USD", err: currency.ErrEmptyAmount, msg: "empty amount"},
+		{value: "-1.00", code: "USD", err: currency.ErrNegativeAmount, msg: "amount must not be negative"},
+		{value: "+1.00", code: "USD", err: currency.ErrMalformedAmount, msg: `amount must be a decimal number like 12.34, got "+1.00"`},
+		{value: "1,00", code: "USD", err: currency.ErrMalformedAmount, msg: `amount must be a decimal number like 12.34, got "1,00"`},
+		{value: ".50", code: "USD", err: currency.ErrMalformedAmount, msg: `amount must be a decimal number like 12.34, got ".50"`},
+		{value: "1.", code: "USD", err: currency.ErrMalformedAmount, msg: `amount must be a decimal number like 12.34, got "1."`},
+		{value: "1.2.3", code: "USD", err: currency.ErrMalformedAmount, msg: `amount must be a decimal number like 12.34, got "1.2.3"`},
+		{value: "1e3", code: "USD", err: currency.ErrMalformedAmount, msg: `amount must be a decimal number like 12.34, got "1e3"`},
+		{value: " 1", code: "USD", err: currency.ErrMalformedAmount, msg: `amount must be a decimal number like 12.34, got " 1"`},
+		{value: "12.345", code: "USD", err: currency.ErrExcessPrecision, msg: "amount has too many decimal places, USD allows at most 2"},
+		{value: "1.0", code: "JPY", err: currency.ErrExcessPrecision, msg: "amount has too many decimal places, JPY has no minor units"},
+		{value: "92233720368547758.08", code: "USD", err: currency.ErrAmountOutOfRange, msg: "amount is out of range"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.value+" "+tc.code, func(t *testing.T) {
+			_, err := currency.ParseDecimal(tc.value, tc.code)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, tc.err))
+			assert.EqualError(t, err, tc.msg)
+		})
+	}
+}
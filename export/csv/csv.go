@@ -1,42 +1,67 @@
 package csv
 
 import (
-	"bytes"
 	"encoding/csv"
+	"io"
 
 	"github.com/justteddy/wallet/types"
 	"github.com/pkg/errors"
 )
 
-var headers = []string{"wallet_id", "operation_id", "amount", "date"}
+// headers are in the order of fields of types.ExportOperation and have names of its JSON fields
+var headers = []string{"id", "wallet_id", "operation_type", "amount", "currency", "transfer_id", "counterparty_wallet_id", "reversal_of", "source_amount", "destination_amount", "fx_rate", "used_credit", "remaining_credit", "balance", "date"}
 
-func Format(ops []types.ExportOperation) ([]byte, error) {
-	if len(ops) == 0 {
-		return []byte{}, nil
+// Format writes operations of the page row by row, the closing balance row precedes operations of the first page
+// and the opening balance row follows operations of the last page,
+// the next page cursor is returned by the handler in Link header
+func Format(w io.Writer, page types.ExportPage) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(headers); err != nil {
+		return errors.Wrap(err, "write csv headers")
 	}
 
-	buffer := bytes.NewBuffer(nil)
-	w := csv.NewWriter(buffer)
-	if err := w.Write(headers); err != nil {
-		return nil, errors.Wrap(err, "write csv headers")
+	if page.FirstPage {
+		if err := cw.Write(transformToStrings(page.ClosingBalance)); err != nil {
+			return errors.Wrap(err, "write csv data")
+		}
 	}
 
-	if err := w.WriteAll(transformToStringSlice(ops)); err != nil {
-		return nil, errors.Wrap(err, "write csv data")
+	for page.Operations.Next() {
+		if err := cw.Write(transformToStrings(page.Operations.Operation())); err != nil {
+			return errors.Wrap(err, "write csv data")
+		}
 	}
 
-	return buffer.Bytes(), nil
+	if err := page.Operations.Err(); err != nil {
+		return errors.Wrap(err, "read operations")
+	}
+
+	if page.NextCursor == "" {
+		if err := cw.Write(transformToStrings(page.OpeningBalance)); err != nil {
+			return errors.Wrap(err, "write csv data")
+		}
+	}
+
+	cw.Flush()
+	return errors.Wrap(cw.Error(), "write csv data")
 }
 
-func transformToStringSlice(ops []types.ExportOperation) [][]string {
-	result := make([][]string, 0, len(ops))
-	for _, op := range ops {
-		result = append(result, []string{
-			op.WalletID,
-			op.OperationType,
-			op.Amount,
-			op.Date,
-		})
+func transformToStrings(op types.ExportOperation) []string {
+	return []string{
+		op.ID,
+		op.WalletID,
+		op.OperationType,
+		op.Amount,
+		op.Currency,
+		op.TransferID,
+		op.CounterpartyWalletID,
+		op.ReversalOf,
+		op.SourceAmount,
+		op.DestinationAmount,
+		op.FXRate,
+		op.UsedCredit,
+		op.RemainingCredit,
+		op.Balance,
+		op.Date,
 	}
-	return result
 }
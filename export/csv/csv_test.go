@@ -1,6 +1,8 @@
 package csv_test
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/justteddy/wallet/export/csv"
@@ -11,33 +13,91 @@ import (
 
 func TestFormat(t *testing.T) {
 	t.Run("empty operations", func(t *testing.T) {
-		data, err := csv.Format([]types.ExportOperation{})
+		buf := &bytes.Buffer{}
+		err := csv.Format(buf, types.ExportPage{
+			Operations:     &operations{},
+			FirstPage:      true,
+			OpeningBalance: types.ExportOperation{WalletID: "wallet1", OperationType: "opening_balance", Currency: "USD", Balance: "0.00$", Date: "2030-01-01"},
+			ClosingBalance: types.ExportOperation{WalletID: "wallet1", OperationType: "closing_balance", Currency: "USD", Balance: "0.00$"},
+		})
+
+		expected := `id,wallet_id,operation_type,amount,currency,transfer_id,counterparty_wallet_id,reversal_of,source_amount,destination_amount,fx_rate,used_credit,remaining_credit,balance,date
+,wallet1,closing_balance,,USD,,,,,,,,,0.00$,
+,wallet1,opening_balance,,USD,,,,,,,,,0.00$,2030-01-01
+`
+
 		require.NoError(t, err)
-		assert.Equal(t, []byte{}, data)
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := csv.Format(buf, types.ExportPage{Operations: &operations{err: errors.New("storage error")}})
+		assert.EqualError(t, err, "read operations: storage error")
 	})
 
 	t.Run("not empty operations", func(t *testing.T) {
-		data, err := csv.Format([]types.ExportOperation{
+		buf := &bytes.Buffer{}
+		err := csv.Format(buf, types.ExportPage{Operations: &operations{ops: []types.ExportOperation{
 			{
-				WalletID:      "wallet1",
-				OperationType: "operation",
-				Amount:        "100.00$",
-				Date:          "2030-01-01",
+				ID:              "1",
+				WalletID:        "wallet1",
+				OperationType:   "operation",
+				Amount:          "100.00$",
+				Currency:        "USD",
+				UsedCredit:      "50.00$",
+				RemainingCredit: "150.00$",
+				Balance:         "100.00$",
+				Date:            "2030-01-01",
 			},
 			{
-				WalletID:      "wallet2",
-				OperationType: "operation2",
-				Amount:        "200.00$",
-				Date:          "2030-01-02",
+				ID:                   "2",
+				WalletID:             "wallet2",
+				OperationType:        "operation2",
+				Amount:               "200.00€",
+				Currency:             "EUR",
+				TransferID:           "transfer1",
+				CounterpartyWalletID: "wallet1",
+				ReversalOf:           "1",
+				SourceAmount:         "218.48$",
+				DestinationAmount:    "200.00€",
+				FXRate:               "0.9154",
+				Balance:              "300.00€",
+				Date:                 "2030-01-02",
 			},
-		})
+		}}, NextCursor: "cursor"})
 
-		expected := []byte(`wallet_id,operation_id,amount,date
-wallet1,operation,100.00$,2030-01-01
-wallet2,operation2,200.00$,2030-01-02
-`)
+		// middle page doesn't have balance rows
+
+		expected := `id,wallet_id,operation_type,amount,currency,transfer_id,counterparty_wallet_id,reversal_of,source_amount,destination_amount,fx_rate,used_credit,remaining_credit,balance,date
+1,wallet1,operation,100.00$,USD,,,,,,,50.00$,150.00$,100.00$,2030-01-01
+2,wallet2,operation2,200.00€,EUR,transfer1,wallet1,1,218.48$,200.00€,0.9154,,,300.00€,2030-01-02
+`
 
 		require.NoError(t, err)
-		assert.Equal(t, expected, data)
+		assert.Equal(t, expected, buf.String())
 	})
 }
+
+// operations iterates over ops and fails with err at the end if it's set
+type operations struct {
+	ops []types.ExportOperation
+	cur int
+	err error
+}
+
+func (o *operations) Next() bool {
+	if o.cur >= len(o.ops) {
+		return false
+	}
+	o.cur++
+	return true
+}
+
+func (o *operations) Operation() types.ExportOperation {
+	return o.ops[o.cur-1]
+}
+
+func (o *operations) Err() error {
+	return o.err
+}
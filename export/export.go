@@ -1,13 +1,15 @@
 package export
 
 import (
+	"io"
+
 	"github.com/justteddy/wallet/export/csv"
 	"github.com/justteddy/wallet/export/json"
 	"github.com/justteddy/wallet/types"
 	"github.com/pkg/errors"
 )
 
-type exportFunc func(ops []types.ExportOperation) ([]byte, error)
+type exportFunc func(w io.Writer, page types.ExportPage) error
 
 type exporter struct {
 	toJSON exportFunc
@@ -21,14 +23,14 @@ func New() *exporter {
 	}
 }
 
-// Export marshals []types.ExportOperation to []byte using different formats
-func (e *exporter) Export(format types.ExportFormat, ops []types.ExportOperation) ([]byte, error) {
+// Export writes types.ExportPage to w using different formats while operations are read from the page
+func (e *exporter) Export(w io.Writer, format types.ExportFormat, page types.ExportPage) error {
 	switch format {
 	case types.ExportFormatJSON:
-		return e.toJSON(ops)
+		return e.toJSON(w, page)
 	case types.ExportFormatCSV:
-		return e.toCSV(ops)
+		return e.toCSV(w, page)
 	default:
-		return nil, errors.New("unexpected export format")
+		return errors.New("unexpected export format")
 	}
 }
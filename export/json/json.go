@@ -2,10 +2,53 @@ package json
 
 import (
 	"encoding/json"
+	"io"
 
 	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
 )
 
-func Format(ops []types.ExportOperation) ([]byte, error) {
-	return json.Marshal(ops)
+// Format writes page as {"opening_balance":"...","closing_balance":"...","operations":[...],"next_cursor":"..."},
+// operations are encoded one by one
+func Format(w io.Writer, page types.ExportPage) error {
+	balances, err := json.Marshal(struct {
+		OpeningBalance string `json:"opening_balance"`
+		ClosingBalance string `json:"closing_balance"`
+	}{
+		OpeningBalance: page.OpeningBalance.Balance,
+		ClosingBalance: page.ClosingBalance.Balance,
+	})
+	if err != nil {
+		return errors.Wrap(err, "marshal balances")
+	}
+
+	// balances object is left open to continue with operations
+	if _, err := w.Write(append(balances[:len(balances)-1], `,"operations":[`...)); err != nil {
+		return errors.Wrap(err, "write json")
+	}
+
+	for i := 0; page.Operations.Next(); i++ {
+		data, err := json.Marshal(page.Operations.Operation())
+		if err != nil {
+			return errors.Wrap(err, "marshal operation")
+		}
+		if i > 0 {
+			data = append([]byte{','}, data...)
+		}
+		if _, err := w.Write(data); err != nil {
+			return errors.Wrap(err, "write json")
+		}
+	}
+
+	if err := page.Operations.Err(); err != nil {
+		return errors.Wrap(err, "read operations")
+	}
+
+	cursor, err := json.Marshal(page.NextCursor)
+	if err != nil {
+		return errors.Wrap(err, "marshal cursor")
+	}
+
+	_, err = io.WriteString(w, `],"next_cursor":`+string(cursor)+`}`)
+	return errors.Wrap(err, "write json")
 }
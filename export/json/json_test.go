@@ -1,6 +1,8 @@
 package json_test
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/justteddy/wallet/export/json"
@@ -10,23 +12,85 @@ import (
 )
 
 func TestFormat(t *testing.T) {
-	data, err := json.Format([]types.ExportOperation{
-		{
-			WalletID:      "wallet1",
-			OperationType: "operation",
-			Amount:        "100.00$",
-			Date:          "2030-01-01",
-		},
-		{
-			WalletID:      "wallet2",
-			OperationType: "operation2",
-			Amount:        "200.00$",
-			Date:          "2030-01-02",
-		},
+	t.Run("empty operations", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := json.Format(buf, types.ExportPage{
+			Operations:     &operations{},
+			OpeningBalance: types.ExportOperation{Balance: "0.00$"},
+			ClosingBalance: types.ExportOperation{Balance: "1.00$"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `{"opening_balance":"0.00$","closing_balance":"1.00$","operations":[],"next_cursor":""}`, buf.String())
 	})
 
-	expected := []byte(`[{"wallet_id":"wallet1","operation_type":"operation","amount":"100.00$","date":"2030-01-01"},{"wallet_id":"wallet2","operation_type":"operation2","amount":"200.00$","date":"2030-01-02"}]`)
+	t.Run("read error", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := json.Format(buf, types.ExportPage{Operations: &operations{err: errors.New("storage error")}})
+		assert.EqualError(t, err, "read operations: storage error")
+	})
+
+	t.Run("not empty operations", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := json.Format(buf, types.ExportPage{
+			Operations: &operations{ops: []types.ExportOperation{
+				{
+					ID:              "1",
+					WalletID:        "wallet1",
+					OperationType:   "operation",
+					Amount:          "100.00$",
+					Currency:        "USD",
+					UsedCredit:      "50.00$",
+					RemainingCredit: "150.00$",
+					Balance:         "100.00$",
+					Date:            "2030-01-01",
+				},
+				{
+					ID:                   "2",
+					WalletID:             "wallet2",
+					OperationType:        "operation2",
+					Amount:               "200.00€",
+					Currency:             "EUR",
+					TransferID:           "transfer1",
+					CounterpartyWalletID: "wallet1",
+					ReversalOf:           "1",
+					SourceAmount:         "218.48$",
+					DestinationAmount:    "200.00€",
+					FXRate:               "0.9154",
+					Balance:              "300.00€",
+					Date:                 "2030-01-02",
+				},
+			}},
+			NextCursor:     "cursor",
+			OpeningBalance: types.ExportOperation{Balance: "0.00$"},
+			ClosingBalance: types.ExportOperation{Balance: "300.00$"},
+		})
+
+		expected := `{"opening_balance":"0.00$","closing_balance":"300.00$","operations":[{"id":"1","wallet_id":"wallet1","operation_type":"operation","amount":"100.00$","currency":"USD","transfer_id":"","counterparty_wallet_id":"","reversal_of":"","source_amount":"","destination_amount":"","fx_rate":"","used_credit":"50.00$","remaining_credit":"150.00$","balance":"100.00$","date":"2030-01-01"},{"id":"2","wallet_id":"wallet2","operation_type":"operation2","amount":"200.00€","currency":"EUR","transfer_id":"transfer1","counterparty_wallet_id":"wallet1","reversal_of":"1","source_amount":"218.48$","destination_amount":"200.00€","fx_rate":"0.9154","used_credit":"","remaining_credit":"","balance":"300.00€","date":"2030-01-02"}],"next_cursor":"cursor"}`
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, buf.String())
+	})
+}
+
+// operations iterates over ops and fails with err at the end if it's set
+type operations struct {
+	ops []types.ExportOperation
+	cur int
+	err error
+}
+
+func (o *operations) Next() bool {
+	if o.cur >= len(o.ops) {
+		return false
+	}
+	o.cur++
+	return true
+}
+
+func (o *operations) Operation() types.ExportOperation {
+	return o.ops[o.cur-1]
+}
 
-	require.NoError(t, err)
-	assert.Equal(t, expected, data)
+func (o *operations) Err() error {
+	return o.err
 }
@@ -0,0 +1,127 @@
+package fx
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+
+	"github.com/justteddy/wallet/currency"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+// RateScale is the number of decimal places of stored rates
+const RateScale = 12
+
+var (
+	errInvalidRate        = errors.New("rate must be a positive decimal number")
+	errSameCurrencies     = errors.New("base and quote currencies must differ")
+	errEmptyEffectiveFrom = errors.New("effective_from is required")
+)
+
+// ParseRate parses positive decimal rate
+func ParseRate(s string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok || rate.Sign() <= 0 {
+		return nil, errInvalidRate
+	}
+	return rate, nil
+}
+
+// ApplySpread reduces rate by spread in basis points and rounds it down to RateScale decimal places,
+// so the rounded rate can be stored and used for the conversion as is
+func ApplySpread(rate *big.Rat, spreadBps int) *big.Rat {
+	applied := new(big.Rat).Mul(rate, big.NewRat(int64(10_000-spreadBps), 10_000))
+	return roundDown(applied, RateScale)
+}
+
+// ValidateSpread checks that spread in basis points keeps the rate positive and doesn't raise it above the mid rate
+func ValidateSpread(spreadBps int) error {
+	if spreadBps < 0 || spreadBps >= 10_000 {
+		return errors.Errorf("spread must be from 0 to 9999 basis points, got %d", spreadBps)
+	}
+	return nil
+}
+
+// Convert converts amount in minor units of `from` currency to minor units of `to` currency by rate,
+// which is the price of one major unit of `from` currency in major units of `to` currency.
+// The result is rounded down.
+func Convert(amount types.Money, from, to string, rate *big.Rat) (types.Money, error) {
+	converted := new(big.Rat).Mul(big.NewRat(int64(amount), 1), rate)
+	converted.Mul(converted, new(big.Rat).SetFrac(pow10(exponent(to)), pow10(exponent(from))))
+
+	result := new(big.Int).Quo(converted.Num(), converted.Denom())
+	if !result.IsInt64() {
+		return 0, types.ErrAmountOverflow
+	}
+	return types.Money(result.Int64()), nil
+}
+
+// FormatRate formats rate with RateScale decimal places
+func FormatRate(rate *big.Rat) string {
+	return rate.FloatString(RateScale)
+}
+
+// Validate checks that rate can be saved
+func Validate(rate types.FXRate) error {
+	if _, ok := currency.Lookup(rate.BaseCurrency); !ok {
+		return errors.Errorf("unsupported base currency %q", rate.BaseCurrency)
+	}
+
+	if _, ok := currency.Lookup(rate.QuoteCurrency); !ok {
+		return errors.Errorf("unsupported quote currency %q", rate.QuoteCurrency)
+	}
+
+	if rate.BaseCurrency == rate.QuoteCurrency {
+		return errSameCurrencies
+	}
+
+	if _, err := ParseRate(rate.Rate); err != nil {
+		return err
+	}
+
+	if rate.EffectiveFrom.IsZero() {
+		return errEmptyEffectiveFrom
+	}
+
+	return nil
+}
+
+// LoadFile reads rates from JSON file with array of rates and validates them
+func LoadFile(path string) ([]types.FXRate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read rates file")
+	}
+
+	var rates []types.FXRate
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, errors.Wrap(err, "decode rates file")
+	}
+
+	for i, rate := range rates {
+		if err := Validate(rate); err != nil {
+			return nil, errors.Wrapf(err, "rate %d", i)
+		}
+	}
+
+	return rates, nil
+}
+
+func roundDown(r *big.Rat, scale int) *big.Rat {
+	unit := pow10(scale)
+	scaled := new(big.Int).Mul(r.Num(), unit)
+	scaled.Quo(scaled, r.Denom())
+	return new(big.Rat).SetFrac(scaled, unit)
+}
+
+func exponent(code string) int {
+	if c, ok := currency.Lookup(code); ok {
+		return c.Exponent
+	}
+	return 2
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
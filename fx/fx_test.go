@@ -0,0 +1,162 @@
+package fx_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/justteddy/wallet/fx"
+	"github.com/justteddy/wallet/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	cases := []struct {
+		name     string
+		amount   types.Money
+		from     string
+		to       string
+		rate     string
+		spread   int
+		expected types.Money
+	}{
+		{
+			name:     "same exponent",
+			amount:   10000,
+			from:     "USD",
+			to:       "EUR",
+			rate:     "0.9154",
+			expected: 9154,
+		},
+		{
+			name:     "rounded down",
+			amount:   1,
+			from:     "USD",
+			to:       "EUR",
+			rate:     "0.9154",
+			expected: 0,
+		},
+		{
+			name:     "to currency without minor units",
+			amount:   1050,
+			from:     "USD",
+			to:       "JPY",
+			rate:     "149.5",
+			expected: 1569,
+		},
+		{
+			name:     "from currency without minor units",
+			amount:   1000,
+			from:     "JPY",
+			to:       "USD",
+			rate:     "0.0067",
+			expected: 670,
+		},
+		{
+			name:     "to currency with 3 decimal places",
+			amount:   100,
+			from:     "USD",
+			to:       "BHD",
+			rate:     "0.376",
+			expected: 376,
+		},
+		{
+			name:     "with spread",
+			amount:   10000,
+			from:     "USD",
+			to:       "EUR",
+			rate:     "0.9154",
+			spread:   100,
+			expected: 9062,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rate, err := fx.ParseRate(c.rate)
+			require.NoError(t, err)
+
+			converted, err := fx.Convert(c.amount, c.from, c.to, fx.ApplySpread(rate, c.spread))
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, converted)
+		})
+	}
+}
+
+func TestConvertOverflow(t *testing.T) {
+	rate, err := fx.ParseRate("149.5")
+	require.NoError(t, err)
+
+	_, err = fx.Convert(math.MaxInt64/100, "JPY", "USD", rate)
+	assert.Equal(t, types.ErrAmountOverflow, err)
+}
+
+func TestApplySpread(t *testing.T) {
+	rate, err := fx.ParseRate("1.1")
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.100000000000", fx.FormatRate(fx.ApplySpread(rate, 0)))
+	assert.Equal(t, "1.094500000000", fx.FormatRate(fx.ApplySpread(rate, 50)))
+
+	rate, err = fx.ParseRate("0.333333333333333")
+	require.NoError(t, err)
+	assert.Equal(t, "0.333333333333", fx.FormatRate(fx.ApplySpread(rate, 0)))
+}
+
+func TestValidate(t *testing.T) {
+	valid := types.FXRate{
+		BaseCurrency:  "USD",
+		QuoteCurrency: "EUR",
+		Rate:          "0.9154",
+		EffectiveFrom: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, fx.Validate(valid))
+
+	cases := []struct {
+		name     string
+		modify   func(r *types.FXRate)
+		expected string
+	}{
+		{
+			name:     "unsupported currency",
+			modify:   func(r *types.FXRate) { r.QuoteCurrency = "XXX" },
+			expected: `unsupported quote currency "XXX"`,
+		},
+		{
+			name:     "same currencies",
+			modify:   func(r *types.FXRate) { r.QuoteCurrency = "USD" },
+			expected: "base and quote currencies must differ",
+		},
+		{
+			name:     "negative rate",
+			modify:   func(r *types.FXRate) { r.Rate = "-1" },
+			expected: "rate must be a positive decimal number",
+		},
+		{
+			name:     "malformed rate",
+			modify:   func(r *types.FXRate) { r.Rate = "1,5" },
+			expected: "rate must be a positive decimal number",
+		},
+		{
+			name:     "empty effective time",
+			modify:   func(r *types.FXRate) { r.EffectiveFrom = time.Time{} },
+			expected: "effective_from is required",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rate := valid
+			c.modify(&rate)
+			assert.EqualError(t, fx.Validate(rate), c.expected)
+		})
+	}
+}
+
+func TestValidateSpread(t *testing.T) {
+	assert.NoError(t, fx.ValidateSpread(0))
+	assert.NoError(t, fx.ValidateSpread(9999))
+	assert.EqualError(t, fx.ValidateSpread(-1), "spread must be from 0 to 9999 basis points, got -1")
+	assert.EqualError(t, fx.ValidateSpread(10000), "spread must be from 0 to 9999 basis points, got 10000")
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+)
+
+// AdminOnly passes to next handler only requests with `Authorization: Bearer <token>` header,
+// all requests are rejected if token is empty, so admin endpoints are disabled without token
+func AdminOnly(token string, next httprouter.Handle) httprouter.Handle {
+	expected := []byte("Bearer " + token)
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		if token == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			writeErrorResponse(w, http.StatusUnauthorized, errors.New("unauthorized"))
+			return
+		}
+		next(w, r, params)
+	}
+}
@@ -0,0 +1,63 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminOnly(t *testing.T) {
+	next := func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	cases := []struct {
+		name          string
+		token         string
+		authorization string
+		expectedCode  int
+	}{
+		{
+			name:          "empty token disables endpoint",
+			token:         "",
+			authorization: "Bearer ",
+			expectedCode:  http.StatusUnauthorized,
+		},
+		{
+			name:          "missing authorization",
+			token:         "secret",
+			authorization: "",
+			expectedCode:  http.StatusUnauthorized,
+		},
+		{
+			name:          "wrong token",
+			token:         "secret",
+			authorization: "Bearer wrong",
+			expectedCode:  http.StatusUnauthorized,
+		},
+		{
+			name:          "valid token",
+			token:         "secret",
+			authorization: "Bearer secret",
+			expectedCode:  http.StatusOK,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/admin", nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", c.authorization)
+
+			rr := httptest.NewRecorder()
+			handlers.AdminOnly(c.token, next)(rr, req, nil)
+
+			assert.Equal(t, c.expectedCode, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/justteddy/wallet/currency"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+var errAmountsMutuallyExclusive = errors.New("amount and decimal_amount are mutually exclusive")
+
+// walletAmountRequest is the body of deposit and withdrawal
+type walletAmountRequest struct {
+	Amount        types.Money `json:"amount"`
+	DecimalAmount string      `json:"decimal_amount,omitempty"`
+}
+
+// parseWalletAmount decodes amount of the request to the wallet and builds idempotency key for the operation scope,
+// erroneous response is written if it fails. The request is hashed with the parsed decimal amount,
+// so it's equal to the one with the same amount in minor units.
+func (h *Handler) parseWalletAmount(w http.ResponseWriter, r *http.Request, wallet types.WalletID, scope string) (types.Money, *types.IdempotencyKey, bool) {
+	var req walletAmountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "decode request"))
+		return 0, nil, false
+	}
+
+	if req.DecimalAmount != "" {
+		if req.Amount != 0 {
+			writeErrorResponse(w, http.StatusBadRequest, errAmountsMutuallyExclusive)
+			return 0, nil, false
+		}
+
+		amount, ok := h.decimalAmount(w, r, wallet, "wallet", req.DecimalAmount)
+		if !ok {
+			return 0, nil, false
+		}
+		req.Amount, req.DecimalAmount = amount, ""
+	}
+
+	if req.Amount <= 0 {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("invalid amount"))
+		return 0, nil, false
+	}
+
+	idemKey, err := idempotencyKey(r, scope, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err)
+		return 0, nil, false
+	}
+
+	return req.Amount, idemKey, true
+}
+
+// decimalAmount parses decimal amount in currency of the wallet, erroneous response is written if it fails.
+// field is the name of request field with the wallet.
+func (h *Handler) decimalAmount(w http.ResponseWriter, r *http.Request, wallet types.WalletID, field, value string) (types.Money, bool) {
+	details, err := h.s.Wallet(r.Context(), wallet)
+	if err != nil {
+		if errors.Cause(err) == types.ErrWalletNotFound {
+			writeErrorResponse(w, http.StatusNotFound, &types.WalletNotFoundError{Field: field})
+			return 0, false
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "fetch wallet"))
+		return 0, false
+	}
+
+	amount, err := currency.ParseDecimal(value, details.Currency)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "invalid decimal_amount"))
+		return 0, false
+	}
+
+	if amount == 0 {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("invalid amount"))
+		return 0, false
+	}
+
+	return types.Money(amount), true
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxBatchLegs limits the size of the batch, the whole batch is executed in one transaction
+const maxBatchLegs = 1000
+
+type batchTransferLeg struct {
+	ToWallet types.WalletID `json:"to_wallet"`
+	Amount   types.Money    `json:"amount"`
+}
+
+type batchTransferRequest struct {
+	FromWallet types.WalletID     `json:"from_wallet"`
+	Legs       []batchTransferLeg `json:"legs"`
+}
+
+func (h *Handler) HandleBatchTransfer(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var batchReq batchTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&batchReq); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "decode request"))
+		return
+	}
+
+	if err := validateBatchTransferRequest(batchReq); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	idemKey, err := idempotencyKey(r, "transfer_batch", batchReq)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	legs := make([]types.BatchLeg, 0, len(batchReq.Legs))
+	for _, leg := range batchReq.Legs {
+		legs = append(legs, types.BatchLeg{ToWallet: leg.ToWallet, Amount: leg.Amount})
+	}
+
+	batch, err := h.s.BatchTransfer(r.Context(), batchReq.FromWallet, legs, idemKey)
+	if err != nil {
+		switch errors.Cause(err) {
+		case types.ErrWalletNotFound:
+			writeErrorResponse(w, http.StatusNotFound, walletNotFoundError(err))
+			return
+		case types.ErrWalletFrozen:
+			writeErrorResponse(w, http.StatusForbidden, walletStatusError(err))
+			return
+		case types.ErrWalletClosed:
+			writeErrorResponse(w, http.StatusConflict, walletStatusError(err))
+			return
+		case types.ErrUnavailableBalance, types.ErrFXRateNotFound, types.ErrConvertedAmountTooSmall, types.ErrAmountOverflow:
+			writeErrorResponse(w, http.StatusBadRequest, batchLegError(err))
+			return
+		case types.ErrLimitExceeded:
+			writeLimitExceededResponse(w, err)
+			return
+		case types.ErrIdempotencyKeyReused:
+			writeErrorResponse(w, http.StatusConflict, types.ErrIdempotencyKeyReused)
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "save to storage"))
+		return
+	}
+
+	resp, err := json.Marshal(&batch)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "marshal response"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		log.WithError(err).Error("failed to write successful response")
+	}
+}
+
+func validateBatchTransferRequest(batchReq batchTransferRequest) error {
+	if batchReq.FromWallet == "" {
+		return errors.New("from_wallet is required")
+	}
+
+	if len(batchReq.Legs) == 0 {
+		return errors.New("legs are required")
+	}
+
+	if len(batchReq.Legs) > maxBatchLegs {
+		return errors.Errorf("too many legs, max %d", maxBatchLegs)
+	}
+
+	for i, leg := range batchReq.Legs {
+		if leg.ToWallet == "" {
+			return errors.Errorf("legs[%d].to_wallet is required", i)
+		}
+
+		if leg.ToWallet == batchReq.FromWallet {
+			return errors.Errorf("legs[%d]: similar wallets provided", i)
+		}
+
+		if leg.Amount <= 0 {
+			return errors.Errorf("legs[%d]: invalid amount", i)
+		}
+	}
+
+	return nil
+}
+
+// batchLegError returns the cause of err which points to the failed leg of the batch if err contains such one
+func batchLegError(err error) error {
+	var legErr *types.BatchLegError
+	if errors.As(err, &legErr) {
+		return &types.BatchLegError{Index: legErr.Index, Err: errors.Cause(legErr.Err)}
+	}
+	return errors.Cause(err)
+}
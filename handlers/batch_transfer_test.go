@@ -0,0 +1,173 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleBatchTransfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	validationErrors := []struct {
+		name         string
+		body         string
+		expectedBody string
+	}{
+		{
+			name:         "missing from_wallet",
+			body:         `{"legs": [{"to_wallet": "wallet2", "amount": 100}]}`,
+			expectedBody: `{"error":"from_wallet is required"}`,
+		},
+		{
+			name:         "missing legs",
+			body:         `{"from_wallet": "wallet1", "legs": []}`,
+			expectedBody: `{"error":"legs are required"}`,
+		},
+		{
+			name:         "too many legs",
+			body:         `{"from_wallet": "wallet1", "legs": [` + strings.Repeat(`{"to_wallet": "wallet2", "amount": 1},`, 1000) + `{"to_wallet": "wallet2", "amount": 1}]}`,
+			expectedBody: `{"error":"too many legs, max 1000"}`,
+		},
+		{
+			name:         "missing to_wallet",
+			body:         `{"from_wallet": "wallet1", "legs": [{"to_wallet": "wallet2", "amount": 100}, {"amount": 100}]}`,
+			expectedBody: `{"error":"legs[1].to_wallet is required"}`,
+		},
+		{
+			name:         "similar wallets",
+			body:         `{"from_wallet": "wallet1", "legs": [{"to_wallet": "wallet1", "amount": 100}]}`,
+			expectedBody: `{"error":"legs[0]: similar wallets provided"}`,
+		},
+		{
+			name:         "invalid amount",
+			body:         `{"from_wallet": "wallet1", "legs": [{"to_wallet": "wallet2", "amount": 0}]}`,
+			expectedBody: `{"error":"legs[0]: invalid amount"}`,
+		},
+	}
+
+	for _, c := range validationErrors {
+		t.Run("validation error - "+c.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/transfers/batch", bytes.NewReader([]byte(c.body)))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handlers.New(nil, nil, nil).HandleBatchTransfer(rr, req, nil)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, c.expectedBody, rr.Body.String())
+		})
+	}
+
+	legs := []types.BatchLeg{
+		{ToWallet: "wallet2", Amount: 100},
+		{ToWallet: "wallet3", Amount: 250},
+	}
+	body := `{"from_wallet": "wallet1", "legs": [{"to_wallet": "wallet2", "amount": 100}, {"to_wallet": "wallet3", "amount": "250"}]}`
+
+	storageErrors := []struct {
+		name         string
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "storage error",
+			err:          errors.New("storage error"),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"error":"save to storage: rolled back: storage error"}`,
+		},
+		{
+			name:         "wallet of leg not found",
+			err:          &types.WalletNotFoundError{Field: "legs[1].to_wallet"},
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"error":"legs[1].to_wallet not found"}`,
+		},
+		{
+			name:         "wallet of leg is frozen",
+			err:          &types.WalletStatusError{Field: "legs[0].to_wallet", Status: types.WalletStatusFrozen},
+			expectedCode: http.StatusForbidden,
+			expectedBody: `{"error":"legs[0].to_wallet is frozen"}`,
+		},
+		{
+			name:         "insufficient funds for the total",
+			err:          types.ErrUnavailableBalance,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"insufficient funds in the account"}`,
+		},
+		{
+			name:         "exchange rate of leg not found",
+			err:          &types.BatchLegError{Index: 1, Err: errors.Wrap(types.ErrFXRateNotFound, "convert")},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"legs[1]: exchange rate for currencies of wallets is not found"}`,
+		},
+		{
+			name:         "limit of leg exceeded",
+			err:          &types.BatchLegError{Index: 1, Err: &types.LimitExceededError{Limit: types.LimitMaxAmount, Remaining: 200}},
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: `{"error":"transfer limit exceeded","limit":"max_amount","remaining":200}`,
+		},
+	}
+
+	for _, c := range storageErrors {
+		t.Run("storage error - "+c.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/transfers/batch", bytes.NewReader([]byte(body)))
+			require.NoError(t, err)
+
+			storageMock := mocks.NewMockstorage(ctrl)
+			storageMock.EXPECT().
+				BatchTransfer(gomock.Any(), types.WalletID("wallet1"), legs, nil).
+				Times(1).
+				Return(types.TransferBatch{}, errors.Wrap(c.err, "rolled back"))
+
+			rr := httptest.NewRecorder()
+			handlers.New(nil, storageMock, nil).HandleBatchTransfer(rr, req, nil)
+
+			assert.Equal(t, c.expectedCode, rr.Code)
+			assert.Equal(t, c.expectedBody, rr.Body.String())
+		})
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/transfers/batch", bytes.NewReader([]byte(body)))
+		require.NoError(t, err)
+		req.Header.Set("Idempotency-Key", "key")
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			BatchTransfer(gomock.Any(), types.WalletID("wallet1"), legs, gomock.Not(gomock.Nil())).
+			Times(1).
+			Return(types.TransferBatch{
+				ID:          "batchID",
+				FromWallet:  "wallet1",
+				Currency:    "USD",
+				TotalAmount: 350,
+				Legs: []types.BatchLeg{
+					{ToWallet: "wallet2", Amount: 100, TransferID: "transfer1"},
+					{ToWallet: "wallet3", Amount: 250, TransferID: "transfer2"},
+				},
+				CreatedAt: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+			}, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleBatchTransfer(rr, req, nil)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"batch_id":"batchID","from_wallet":"wallet1","currency":"USD","total_amount":350,"legs":[`+
+			`{"to_wallet":"wallet2","amount":100,"transfer_id":"transfer1"},`+
+			`{"to_wallet":"wallet3","amount":250,"transfer_id":"transfer2"}],`+
+			`"created_at":"2022-01-02T03:04:05Z"}`, rr.Body.String())
+	})
+}
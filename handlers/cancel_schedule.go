@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+func (h *Handler) HandleCancelSchedule(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	scheduleID := types.ScheduleID(params.ByName("id"))
+	if !scheduleID.Valid() {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("invalid schedule id"))
+		return
+	}
+
+	if err := h.s.CancelSchedule(r.Context(), scheduleID); err != nil {
+		switch cause := errors.Cause(err); cause {
+		case types.ErrScheduleNotFound:
+			writeErrorResponse(w, http.StatusNotFound, cause)
+			return
+		case types.ErrScheduleNotActive:
+			writeErrorResponse(w, http.StatusConflict, cause)
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "save to storage"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,96 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCancelSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := []httprouter.Param{
+		{
+			Key:   "id",
+			Value: "0c5e8a9d-3f21-4b7a-9e6d-2a8f1c4b7e90",
+		},
+	}
+
+	t.Run("validation error - invalid schedule id", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/schedules/scheduleID/cancel", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleCancelSchedule(rr, req, []httprouter.Param{{Key: "id", Value: "scheduleID"}})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"invalid schedule id"}`, rr.Body.String())
+	})
+
+	storageErrors := []struct {
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			err:          errors.New("storage error"),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"error":"save to storage: storage error"}`,
+		},
+		{
+			err:          types.ErrScheduleNotFound,
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"error":"schedule not found"}`,
+		},
+		{
+			err:          types.ErrScheduleNotActive,
+			expectedCode: http.StatusConflict,
+			expectedBody: `{"error":"schedule is already completed or cancelled"}`,
+		},
+	}
+
+	for _, c := range storageErrors {
+		t.Run("storage error - "+c.err.Error(), func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/schedules/0c5e8a9d-3f21-4b7a-9e6d-2a8f1c4b7e90/cancel", nil)
+			require.NoError(t, err)
+
+			storageMock := mocks.NewMockstorage(ctrl)
+			storageMock.EXPECT().
+				CancelSchedule(gomock.Any(), types.ScheduleID("0c5e8a9d-3f21-4b7a-9e6d-2a8f1c4b7e90")).
+				Times(1).
+				Return(c.err)
+
+			rr := httptest.NewRecorder()
+			handlers.New(nil, storageMock, nil).HandleCancelSchedule(rr, req, params)
+
+			assert.Equal(t, c.expectedCode, rr.Code)
+			assert.Equal(t, c.expectedBody, rr.Body.String())
+		})
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/schedules/0c5e8a9d-3f21-4b7a-9e6d-2a8f1c4b7e90/cancel", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			CancelSchedule(gomock.Any(), types.ScheduleID("0c5e8a9d-3f21-4b7a-9e6d-2a8f1c4b7e90")).
+			Times(1).
+			Return(nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleCancelSchedule(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type createScheduleRequest struct {
+	FromWallet types.WalletID           `json:"from_wallet"`
+	ToWallet   types.WalletID           `json:"to_wallet"`
+	Amount     types.Money              `json:"amount"`
+	Recurrence types.ScheduleRecurrence `json:"recurrence"`
+	StartAt    time.Time                `json:"start_at"`
+	Timezone   string                   `json:"timezone"`
+}
+
+func (h *Handler) HandleCreateSchedule(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var scheduleReq createScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&scheduleReq); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "decode request"))
+		return
+	}
+
+	// monthly occurrences are calculated in the timezone of the client, so they don't move to another day
+	if scheduleReq.Timezone == "" {
+		scheduleReq.Timezone = scheduleReq.StartAt.Format("-07:00")
+	}
+
+	if err := validateCreateScheduleRequest(scheduleReq, time.Now()); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	schedule, err := h.s.CreateSchedule(r.Context(), types.Schedule{
+		FromWallet: scheduleReq.FromWallet,
+		ToWallet:   scheduleReq.ToWallet,
+		Amount:     scheduleReq.Amount,
+		Recurrence: scheduleReq.Recurrence,
+		StartAt:    scheduleReq.StartAt.UTC(),
+		Timezone:   scheduleReq.Timezone,
+	})
+	if err != nil {
+		switch errors.Cause(err) {
+		case types.ErrWalletNotFound:
+			writeErrorResponse(w, http.StatusNotFound, walletNotFoundError(err))
+			return
+		case types.ErrWalletFrozen:
+			writeErrorResponse(w, http.StatusForbidden, walletStatusError(err))
+			return
+		case types.ErrWalletClosed:
+			writeErrorResponse(w, http.StatusConflict, walletStatusError(err))
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "save to storage"))
+		return
+	}
+
+	resp, err := json.Marshal(&schedule)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "marshal response"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		log.WithError(err).Error("failed to write successful response")
+	}
+}
+
+func validateCreateScheduleRequest(scheduleReq createScheduleRequest, now time.Time) error {
+	if scheduleReq.FromWallet == "" {
+		return errors.New("from_wallet is required")
+	}
+
+	if scheduleReq.ToWallet == "" {
+		return errors.New("to_wallet is required")
+	}
+
+	if scheduleReq.FromWallet == scheduleReq.ToWallet {
+		return errors.New("similar wallets provided")
+	}
+
+	if scheduleReq.Amount <= 0 {
+		return errors.New("invalid amount")
+	}
+
+	if _, ok := types.AllScheduleRecurrences[scheduleReq.Recurrence]; !ok {
+		return errors.New("invalid recurrence")
+	}
+
+	if scheduleReq.StartAt.IsZero() {
+		return errors.New("start_at is required")
+	}
+
+	if !scheduleReq.StartAt.After(now) {
+		return errors.New("start_at must be in the future")
+	}
+
+	if _, err := types.LoadScheduleLocation(scheduleReq.Timezone); err != nil {
+		return errors.New("unsupported timezone")
+	}
+
+	return nil
+}
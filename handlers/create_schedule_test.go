@@ -0,0 +1,183 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCreateSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	validationErrors := []struct {
+		name         string
+		body         string
+		expectedBody string
+	}{
+		{
+			name:         "missing from_wallet",
+			body:         `{"to_wallet": "wallet2", "amount": 100, "recurrence": "once", "start_at": "2100-01-01T10:00:00Z"}`,
+			expectedBody: `{"error":"from_wallet is required"}`,
+		},
+		{
+			name:         "missing to_wallet",
+			body:         `{"from_wallet": "wallet1", "amount": 100, "recurrence": "once", "start_at": "2100-01-01T10:00:00Z"}`,
+			expectedBody: `{"error":"to_wallet is required"}`,
+		},
+		{
+			name:         "similar wallets",
+			body:         `{"from_wallet": "wallet1", "to_wallet": "wallet1", "amount": 100, "recurrence": "once", "start_at": "2100-01-01T10:00:00Z"}`,
+			expectedBody: `{"error":"similar wallets provided"}`,
+		},
+		{
+			name:         "invalid amount",
+			body:         `{"from_wallet": "wallet1", "to_wallet": "wallet2", "amount": 0, "recurrence": "once", "start_at": "2100-01-01T10:00:00Z"}`,
+			expectedBody: `{"error":"invalid amount"}`,
+		},
+		{
+			name:         "invalid recurrence",
+			body:         `{"from_wallet": "wallet1", "to_wallet": "wallet2", "amount": 100, "recurrence": "yearly", "start_at": "2100-01-01T10:00:00Z"}`,
+			expectedBody: `{"error":"invalid recurrence"}`,
+		},
+		{
+			name:         "missing start_at",
+			body:         `{"from_wallet": "wallet1", "to_wallet": "wallet2", "amount": 100, "recurrence": "once"}`,
+			expectedBody: `{"error":"start_at is required"}`,
+		},
+		{
+			name:         "start_at in the past",
+			body:         `{"from_wallet": "wallet1", "to_wallet": "wallet2", "amount": 100, "recurrence": "once", "start_at": "2020-01-01T10:00:00Z"}`,
+			expectedBody: `{"error":"start_at must be in the future"}`,
+		},
+		{
+			name:         "unsupported timezone",
+			body:         `{"from_wallet": "wallet1", "to_wallet": "wallet2", "amount": 100, "recurrence": "once", "start_at": "2100-01-01T10:00:00Z", "timezone": "Local"}`,
+			expectedBody: `{"error":"unsupported timezone"}`,
+		},
+	}
+
+	for _, c := range validationErrors {
+		t.Run("validation error - "+c.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/schedules", bytes.NewReader([]byte(c.body)))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handlers.New(nil, nil, nil).HandleCreateSchedule(rr, req, nil)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, c.expectedBody, rr.Body.String())
+		})
+	}
+
+	body := `{"from_wallet": "wallet1", "to_wallet": "wallet2", "amount": 100, "recurrence": "monthly", "start_at": "2100-01-31T12:00:00+02:00"}`
+	startAt := time.Date(2100, time.January, 31, 10, 0, 0, 0, time.UTC)
+	schedule := types.Schedule{
+		FromWallet: "wallet1",
+		ToWallet:   "wallet2",
+		Amount:     100,
+		Recurrence: types.ScheduleRecurrenceMonthly,
+		StartAt:    startAt,
+		Timezone:   "+02:00",
+	}
+
+	storageErrors := []struct {
+		name         string
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "storage error",
+			err:          errors.New("storage error"),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"error":"save to storage: storage error"}`,
+		},
+		{
+			name:         "wallet not found",
+			err:          &types.WalletNotFoundError{Field: "to_wallet"},
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"error":"to_wallet not found"}`,
+		},
+		{
+			name:         "wallet is closed",
+			err:          &types.WalletStatusError{Field: "from_wallet", Status: types.WalletStatusClosed},
+			expectedCode: http.StatusConflict,
+			expectedBody: `{"error":"from_wallet is closed"}`,
+		},
+	}
+
+	for _, c := range storageErrors {
+		t.Run("storage error - "+c.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/schedules", bytes.NewReader([]byte(body)))
+			require.NoError(t, err)
+
+			storageMock := mocks.NewMockstorage(ctrl)
+			storageMock.EXPECT().
+				CreateSchedule(gomock.Any(), schedule).
+				Times(1).
+				Return(types.Schedule{}, c.err)
+
+			rr := httptest.NewRecorder()
+			handlers.New(nil, storageMock, nil).HandleCreateSchedule(rr, req, nil)
+
+			assert.Equal(t, c.expectedCode, rr.Code)
+			assert.Equal(t, c.expectedBody, rr.Body.String())
+		})
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/schedules", bytes.NewReader([]byte(body)))
+		require.NoError(t, err)
+
+		created := schedule
+		created.ID = "scheduleID"
+		created.NextRunAt = &startAt
+		created.Status = types.ScheduleStatusActive
+		created.CreatedAt = time.Date(2022, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			CreateSchedule(gomock.Any(), schedule).
+			Times(1).
+			Return(created, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleCreateSchedule(rr, req, nil)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"schedule_id":"scheduleID","from_wallet":"wallet1","to_wallet":"wallet2","amount":100,`+
+			`"recurrence":"monthly","start_at":"2100-01-31T10:00:00Z","timezone":"+02:00","next_run_at":"2100-01-31T10:00:00Z",`+
+			`"status":"active","created_at":"2022-01-02T03:04:05Z"}`, rr.Body.String())
+	})
+
+	t.Run("happy path - IANA timezone", func(t *testing.T) {
+		body := `{"from_wallet": "wallet1", "to_wallet": "wallet2", "amount": 100, "recurrence": "monthly", "start_at": "2100-01-31T12:00:00+02:00", "timezone": "Europe/Berlin"}`
+		req, err := http.NewRequest(http.MethodPost, "/schedules", bytes.NewReader([]byte(body)))
+		require.NoError(t, err)
+
+		expected := schedule
+		expected.Timezone = "Europe/Berlin"
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			CreateSchedule(gomock.Any(), expected).
+			Times(1).
+			Return(expected, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleCreateSchedule(rr, req, nil)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
@@ -2,26 +2,48 @@ package handlers
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/currency"
 	"github.com/justteddy/wallet/types"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
+type createWalletRequest struct {
+	Currency string `json:"currency"`
+}
+
 type createWalletResponse struct {
 	WalletID types.WalletID `json:"wallet_id"`
 }
 
 func (h *Handler) HandleCreateWallet(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	// empty body means wallet in default currency
+	var createWalletReq createWalletRequest
+	if err := json.NewDecoder(r.Body).Decode(&createWalletReq); err != nil && err != io.EOF {
+		writeErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "decode request"))
+		return
+	}
+
+	if createWalletReq.Currency == "" {
+		createWalletReq.Currency = currency.DefaultCode
+	}
+
+	if _, ok := currency.Lookup(createWalletReq.Currency); !ok {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("unsupported currency"))
+		return
+	}
+
 	walletID, err := h.wg.Generate()
 	if err != nil {
 		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "generate wallet id"))
 		return
 	}
 
-	if err := h.s.CreateWallet(r.Context(), walletID); err != nil {
+	if err := h.s.CreateWallet(r.Context(), walletID, createWalletReq.Currency); err != nil {
 		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "create wallet"))
 		return
 	}
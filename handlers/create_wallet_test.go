@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"bytes"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -19,7 +20,7 @@ func TestHandleCreateWallet(t *testing.T) {
 	defer ctrl.Finish()
 
 	t.Run("wallet generator error", func(t *testing.T) {
-		req, err := http.NewRequest(http.MethodPost, "/wallet", nil)
+		req, err := http.NewRequest(http.MethodPost, "/wallet", http.NoBody)
 		require.NoError(t, err)
 
 		generatorMock := mocks.NewMockwalletGenerator(ctrl)
@@ -36,7 +37,7 @@ func TestHandleCreateWallet(t *testing.T) {
 	})
 
 	t.Run("storage error", func(t *testing.T) {
-		req, err := http.NewRequest(http.MethodPost, "/wallet", nil)
+		req, err := http.NewRequest(http.MethodPost, "/wallet", http.NoBody)
 		require.NoError(t, err)
 
 		generatorMock := mocks.NewMockwalletGenerator(ctrl)
@@ -44,7 +45,7 @@ func TestHandleCreateWallet(t *testing.T) {
 
 		storageMock := mocks.NewMockstorage(ctrl)
 		storageMock.EXPECT().
-			CreateWallet(gomock.Any(), types.WalletID("walletID")).
+			CreateWallet(gomock.Any(), types.WalletID("walletID"), "USD").
 			Times(1).
 			Return(errors.New("storage error"))
 
@@ -55,8 +56,39 @@ func TestHandleCreateWallet(t *testing.T) {
 		assert.Equal(t, `{"error":"create wallet: storage error"}`, rr.Body.String())
 	})
 
-	t.Run("happy path", func(t *testing.T) {
-		req, err := http.NewRequest(http.MethodPost, "/wallet", nil)
+	t.Run("validation error - unsupported currency", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/wallet", bytes.NewReader([]byte(`{"currency": "XXX"}`)))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleCreateWallet(rr, req, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"unsupported currency"}`, rr.Body.String())
+	})
+
+	t.Run("happy path - default currency", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/wallet", http.NoBody)
+		require.NoError(t, err)
+
+		generatorMock := mocks.NewMockwalletGenerator(ctrl)
+		generatorMock.EXPECT().Generate().Times(1).Return(types.WalletID("walletID"), nil)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			CreateWallet(gomock.Any(), types.WalletID("walletID"), "USD").
+			Times(1).
+			Return(nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(generatorMock, storageMock, nil).HandleCreateWallet(rr, req, nil)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"wallet_id":"walletID"}`, rr.Body.String())
+	})
+
+	t.Run("happy path - currency provided", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/wallet", bytes.NewReader([]byte(`{"currency": "JPY"}`)))
 		require.NoError(t, err)
 
 		generatorMock := mocks.NewMockwalletGenerator(ctrl)
@@ -64,7 +96,7 @@ func TestHandleCreateWallet(t *testing.T) {
 
 		storageMock := mocks.NewMockstorage(ctrl)
 		storageMock.EXPECT().
-			CreateWallet(gomock.Any(), types.WalletID("walletID")).
+			CreateWallet(gomock.Any(), types.WalletID("walletID"), "JPY").
 			Times(1).
 			Return(nil)
 
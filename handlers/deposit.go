@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
@@ -9,10 +8,6 @@ import (
 	"github.com/pkg/errors"
 )
 
-type depositRequest struct {
-	Amount int `json:"amount"`
-}
-
 func (h *Handler) HandleDeposit(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	walletID := params.ByName("wallet")
 	if walletID == "" {
@@ -20,18 +15,29 @@ func (h *Handler) HandleDeposit(w http.ResponseWriter, r *http.Request, params h
 		return
 	}
 
-	var depositReq depositRequest
-	if err := json.NewDecoder(r.Body).Decode(&depositReq); err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "decode request"))
-		return
-	}
-
-	if depositReq.Amount <= 0 {
-		writeErrorResponse(w, http.StatusBadRequest, errors.New("invalid amount"))
+	amount, idemKey, ok := h.parseWalletAmount(w, r, types.WalletID(walletID), "deposit:"+walletID)
+	if !ok {
 		return
 	}
 
-	if err := h.s.Deposit(r.Context(), types.WalletID(walletID), depositReq.Amount); err != nil {
+	if err := h.s.Deposit(r.Context(), types.WalletID(walletID), amount, idemKey); err != nil {
+		switch errors.Cause(err) {
+		case types.ErrWalletNotFound:
+			writeErrorResponse(w, http.StatusNotFound, walletNotFoundError(err))
+			return
+		case types.ErrWalletFrozen:
+			writeErrorResponse(w, http.StatusForbidden, walletStatusError(err))
+			return
+		case types.ErrWalletClosed:
+			writeErrorResponse(w, http.StatusConflict, walletStatusError(err))
+			return
+		case types.ErrAmountOverflow:
+			writeErrorResponse(w, http.StatusBadRequest, types.ErrAmountOverflow)
+			return
+		case types.ErrIdempotencyKeyReused:
+			writeErrorResponse(w, http.StatusConflict, types.ErrIdempotencyKeyReused)
+			return
+		}
 		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "save to storage"))
 		return
 	}
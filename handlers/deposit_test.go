@@ -2,6 +2,8 @@ package handlers_test
 
 import (
 	"bytes"
+	"encoding/json"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -56,7 +58,7 @@ func TestHandleDeposit(t *testing.T) {
 
 		storageMock := mocks.NewMockstorage(ctrl)
 		storageMock.EXPECT().
-			Deposit(gomock.Any(), types.WalletID("walletID"), 100).
+			Deposit(gomock.Any(), types.WalletID("walletID"), types.Money(100), nil).
 			Times(1).
 			Return(errors.New("storage error"))
 
@@ -73,6 +75,329 @@ func TestHandleDeposit(t *testing.T) {
 		assert.Equal(t, `{"error":"save to storage: storage error"}`, rr.Body.String())
 	})
 
+	t.Run("storage error - wallet not found", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/deposit/walletID", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Deposit(gomock.Any(), types.WalletID("walletID"), types.Money(100), nil).
+			Times(1).
+			Return(errors.Wrap(&types.WalletNotFoundError{Field: "wallet"}, "rolled back"))
+
+		params := []httprouter.Param{
+			{
+				Key:   "wallet",
+				Value: "walletID",
+			},
+		}
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleDeposit(rr, req, params)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, `{"error":"wallet not found"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - wallet is frozen", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/deposit/walletID", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Deposit(gomock.Any(), types.WalletID("walletID"), types.Money(100), nil).
+			Times(1).
+			Return(errors.Wrap(&types.WalletStatusError{Field: "wallet", Status: types.WalletStatusFrozen}, "rolled back"))
+
+		params := []httprouter.Param{
+			{
+				Key:   "wallet",
+				Value: "walletID",
+			},
+		}
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleDeposit(rr, req, params)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Equal(t, `{"error":"wallet is frozen"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - wallet is closed", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/deposit/walletID", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Deposit(gomock.Any(), types.WalletID("walletID"), types.Money(100), nil).
+			Times(1).
+			Return(errors.Wrap(&types.WalletStatusError{Field: "wallet", Status: types.WalletStatusClosed}, "rolled back"))
+
+		params := []httprouter.Param{
+			{
+				Key:   "wallet",
+				Value: "walletID",
+			},
+		}
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleDeposit(rr, req, params)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Equal(t, `{"error":"wallet is closed"}`, rr.Body.String())
+	})
+
+	t.Run("validation error - amount is out of range", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"amount": "9223372036854775808"}`))
+		req, err := http.NewRequest(http.MethodPost, "/deposit/walletID", body)
+		require.NoError(t, err)
+
+		params := []httprouter.Param{
+			{
+				Key:   "wallet",
+				Value: "walletID",
+			},
+		}
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleDeposit(rr, req, params)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"decode request: amount is out of range"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - balance overflow", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"amount": "9223372036854775807"}`))
+		req, err := http.NewRequest(http.MethodPost, "/deposit/walletID", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Deposit(gomock.Any(), types.WalletID("walletID"), types.Money(math.MaxInt64), nil).
+			Times(1).
+			Return(errors.Wrap(&types.AmountOverflowError{Op: "add", X: 1, Y: math.MaxInt64}, "rolled back"))
+
+		params := []httprouter.Param{
+			{
+				Key:   "wallet",
+				Value: "walletID",
+			},
+		}
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleDeposit(rr, req, params)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"amount is out of range"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - idempotency key reused", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/deposit/walletID", body)
+		require.NoError(t, err)
+		req.Header.Set("Idempotency-Key", "key")
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Deposit(gomock.Any(), types.WalletID("walletID"), types.Money(100), gomock.Not(gomock.Nil())).
+			Times(1).
+			Return(errors.Wrap(types.ErrIdempotencyKeyReused, "storage"))
+
+		params := []httprouter.Param{
+			{
+				Key:   "wallet",
+				Value: "walletID",
+			},
+		}
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleDeposit(rr, req, params)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Equal(t, `{"error":"idempotency key is already used for another request"}`, rr.Body.String())
+	})
+
+	t.Run("idempotency key - same hash for equal requests", func(t *testing.T) {
+		var keys []*types.IdempotencyKey
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Deposit(gomock.Any(), types.WalletID("walletID"), types.Money(100), gomock.Any()).
+			Times(3).
+			Do(func(_, _, _ interface{}, idemKey *types.IdempotencyKey) {
+				keys = append(keys, idemKey)
+			}).
+			Return(nil)
+
+		params := []httprouter.Param{
+			{
+				Key:   "wallet",
+				Value: "walletID",
+			},
+		}
+		for _, payload := range []string{`{"amount": 100}`, `{ "amount":100 }`, `{"amount": "100"}`} {
+			req, err := http.NewRequest(http.MethodPost, "/deposit/walletID", bytes.NewReader([]byte(payload)))
+			require.NoError(t, err)
+			req.Header.Set("Idempotency-Key", "key")
+
+			rr := httptest.NewRecorder()
+			handlers.New(nil, storageMock, nil).HandleDeposit(rr, req, params)
+			assert.Equal(t, http.StatusOK, rr.Code)
+		}
+
+		require.Len(t, keys, 3)
+		assert.Equal(t, "key", keys[0].Key)
+		assert.NotEmpty(t, keys[0].RequestHash)
+		assert.Equal(t, keys[0], keys[1])
+		assert.Equal(t, keys[0], keys[2])
+	})
+
+	t.Run("validation error - amount and decimal_amount", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"amount": 100, "decimal_amount": "1.00"}`))
+		req, err := http.NewRequest(http.MethodPost, "/deposit/walletID", body)
+		require.NoError(t, err)
+
+		params := []httprouter.Param{
+			{
+				Key:   "wallet",
+				Value: "walletID",
+			},
+		}
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleDeposit(rr, req, params)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"amount and decimal_amount are mutually exclusive"}`, rr.Body.String())
+	})
+
+	t.Run("validation error - decimal_amount excess precision", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"decimal_amount": "1.005"}`))
+		req, err := http.NewRequest(http.MethodPost, "/deposit/walletID", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Wallet(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(types.Wallet{ID: "walletID", Currency: "USD"}, nil)
+
+		params := []httprouter.Param{
+			{
+				Key:   "wallet",
+				Value: "walletID",
+			},
+		}
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleDeposit(rr, req, params)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"invalid decimal_amount: amount has too many decimal places, USD allows at most 2"}`, rr.Body.String())
+	})
+
+	t.Run("validation error - malformed decimal_amount", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"decimal_amount": "1,00"}`))
+		req, err := http.NewRequest(http.MethodPost, "/deposit/walletID", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Wallet(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(types.Wallet{ID: "walletID", Currency: "USD"}, nil)
+
+		params := []httprouter.Param{
+			{
+				Key:   "wallet",
+				Value: "walletID",
+			},
+		}
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleDeposit(rr, req, params)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.True(t, json.Valid(rr.Body.Bytes()))
+		assert.Equal(t, `{"error":"invalid decimal_amount: amount must be a decimal number like 12.34, got \"1,00\""}`, rr.Body.String())
+	})
+
+	t.Run("validation error - zero decimal_amount", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"decimal_amount": "0.00"}`))
+		req, err := http.NewRequest(http.MethodPost, "/deposit/walletID", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Wallet(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(types.Wallet{ID: "walletID", Currency: "USD"}, nil)
+
+		params := []httprouter.Param{
+			{
+				Key:   "wallet",
+				Value: "walletID",
+			},
+		}
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleDeposit(rr, req, params)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"invalid amount"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - decimal_amount wallet not found", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"decimal_amount": "1.00"}`))
+		req, err := http.NewRequest(http.MethodPost, "/deposit/walletID", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Wallet(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(types.Wallet{}, &types.WalletNotFoundError{Field: "wallet"})
+
+		params := []httprouter.Param{
+			{
+				Key:   "wallet",
+				Value: "walletID",
+			},
+		}
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleDeposit(rr, req, params)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, `{"error":"wallet not found"}`, rr.Body.String())
+	})
+
+	t.Run("idempotency key - decimal_amount is hashed as parsed amount", func(t *testing.T) {
+		var keys []*types.IdempotencyKey
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Wallet(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(types.Wallet{ID: "walletID", Currency: "USD"}, nil)
+		storageMock.EXPECT().
+			Deposit(gomock.Any(), types.WalletID("walletID"), types.Money(1234), gomock.Any()).
+			Times(2).
+			Do(func(_, _, _ interface{}, idemKey *types.IdempotencyKey) {
+				keys = append(keys, idemKey)
+			}).
+			Return(nil)
+
+		params := []httprouter.Param{
+			{
+				Key:   "wallet",
+				Value: "walletID",
+			},
+		}
+		for _, payload := range []string{`{"amount": 1234}`, `{"decimal_amount": "12.34"}`} {
+			req, err := http.NewRequest(http.MethodPost, "/deposit/walletID", bytes.NewReader([]byte(payload)))
+			require.NoError(t, err)
+			req.Header.Set("Idempotency-Key", "key")
+
+			rr := httptest.NewRecorder()
+			handlers.New(nil, storageMock, nil).HandleDeposit(rr, req, params)
+			assert.Equal(t, http.StatusOK, rr.Code)
+		}
+
+		require.Len(t, keys, 2)
+		assert.Equal(t, keys[0], keys[1])
+	})
+
 	t.Run("happy path", func(t *testing.T) {
 		body := bytes.NewReader([]byte(`{"amount": 100}`))
 		req, err := http.NewRequest(http.MethodPost, "/deposit/walletID", body)
@@ -80,7 +405,7 @@ func TestHandleDeposit(t *testing.T) {
 
 		storageMock := mocks.NewMockstorage(ctrl)
 		storageMock.EXPECT().
-			Deposit(gomock.Any(), types.WalletID("walletID"), 100).
+			Deposit(gomock.Any(), types.WalletID("walletID"), types.Money(100), nil).
 			Times(1).
 			Return(nil)
 
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/currency"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func (h *Handler) HandleGetOperation(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	operationID := types.OperationID(params.ByName("id"))
+	if !operationID.Valid() {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("invalid operation id"))
+		return
+	}
+
+	op, err := h.s.Operation(r.Context(), operationID)
+	if err != nil {
+		if errors.Cause(err) == types.ErrOperationNotFound {
+			writeErrorResponse(w, http.StatusNotFound, types.ErrOperationNotFound)
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "fetch operation"))
+		return
+	}
+
+	// operation is formatted the same way as in reports without locale and timezone
+	resp, err := json.Marshal(types.TransformDBToExportOperation(op, currency.DefaultOptions, time.UTC))
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "marshal response"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		log.WithError(err).Error("failed to write successful response")
+	}
+}
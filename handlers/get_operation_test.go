@@ -0,0 +1,106 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetOperation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const operationID = "7f3c1a52-9d4e-4b8a-a1f6-2c5e8d9b0a13"
+
+	params := []httprouter.Param{
+		{
+			Key:   "id",
+			Value: operationID,
+		},
+	}
+
+	t.Run("validation error - invalid operation id", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/operations/10", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleGetOperation(rr, req, []httprouter.Param{{Key: "id", Value: "10"}})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"invalid operation id"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - operation not found", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/operations/"+operationID, nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Operation(gomock.Any(), types.OperationID(operationID)).
+			Times(1).
+			Return(types.DBOperation{}, types.ErrOperationNotFound)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleGetOperation(rr, req, params)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, `{"error":"operation not found"}`, rr.Body.String())
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/operations/"+operationID, nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Operation(gomock.Any(), types.OperationID(operationID)).
+			Times(1).
+			Return(types.DBOperation{}, errors.New("storage error"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleGetOperation(rr, req, params)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, `{"error":"fetch operation: storage error"}`, rr.Body.String())
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/operations/"+operationID, nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Operation(gomock.Any(), types.OperationID(operationID)).
+			Times(1).
+			Return(types.DBOperation{
+				ID:                   2,
+				PublicID:             operationID,
+				WalletID:             "wallet1",
+				OperationType:        types.OperationTypeWithdraw,
+				Amount:               2222,
+				Currency:             "USD",
+				TransferID:           "transfer1",
+				CounterpartyWalletID: "wallet2",
+				Balance:              10090,
+				CreatedAt:            time.Date(2021, 11, 25, 14, 20, 9, 887310000, time.UTC),
+			}, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleGetOperation(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"id":"`+operationID+`","wallet_id":"wallet1","operation_type":"withdraw","amount":"22.22$","currency":"USD",`+
+			`"transfer_id":"transfer1","counterparty_wallet_id":"wallet2","reversal_of":"","source_amount":"","destination_amount":"",`+
+			`"fx_rate":"","used_credit":"","remaining_credit":"","balance":"100.90$","date":"2021-11-25T14:20:09.88731Z"}`, rr.Body.String())
+	})
+}
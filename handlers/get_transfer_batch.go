@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func (h *Handler) HandleGetTransferBatch(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	batchID := types.BatchID(params.ByName("id"))
+	if !batchID.Valid() {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("invalid batch id"))
+		return
+	}
+
+	batch, err := h.s.TransferBatch(r.Context(), batchID)
+	if err != nil {
+		if errors.Cause(err) == types.ErrBatchNotFound {
+			writeErrorResponse(w, http.StatusNotFound, types.ErrBatchNotFound)
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "fetch batch"))
+		return
+	}
+
+	resp, err := json.Marshal(&batch)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "marshal response"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		log.WithError(err).Error("failed to write successful response")
+	}
+}
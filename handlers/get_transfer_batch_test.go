@@ -0,0 +1,99 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetTransferBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := []httprouter.Param{
+		{
+			Key:   "id",
+			Value: "c5a1d7e3-0f2b-4a9c-8d6e-1b3f5a7c9e20",
+		},
+	}
+
+	t.Run("validation error - invalid batch id", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/transfers/batch/batchID", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleGetTransferBatch(rr, req, []httprouter.Param{{Key: "id", Value: "batchID"}})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"invalid batch id"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - batch not found", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/transfers/batch/c5a1d7e3-0f2b-4a9c-8d6e-1b3f5a7c9e20", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			TransferBatch(gomock.Any(), types.BatchID("c5a1d7e3-0f2b-4a9c-8d6e-1b3f5a7c9e20")).
+			Times(1).
+			Return(types.TransferBatch{}, types.ErrBatchNotFound)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleGetTransferBatch(rr, req, params)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, `{"error":"batch not found"}`, rr.Body.String())
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/transfers/batch/c5a1d7e3-0f2b-4a9c-8d6e-1b3f5a7c9e20", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			TransferBatch(gomock.Any(), types.BatchID("c5a1d7e3-0f2b-4a9c-8d6e-1b3f5a7c9e20")).
+			Times(1).
+			Return(types.TransferBatch{}, errors.New("storage error"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleGetTransferBatch(rr, req, params)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, `{"error":"fetch batch: storage error"}`, rr.Body.String())
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/transfers/batch/c5a1d7e3-0f2b-4a9c-8d6e-1b3f5a7c9e20", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			TransferBatch(gomock.Any(), types.BatchID("c5a1d7e3-0f2b-4a9c-8d6e-1b3f5a7c9e20")).
+			Times(1).
+			Return(types.TransferBatch{
+				ID:          "c5a1d7e3-0f2b-4a9c-8d6e-1b3f5a7c9e20",
+				FromWallet:  "wallet1",
+				Currency:    "USD",
+				TotalAmount: 100,
+				Legs:        []types.BatchLeg{{ToWallet: "wallet2", Amount: 100, TransferID: "transfer1"}},
+				CreatedAt:   time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+			}, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleGetTransferBatch(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"batch_id":"c5a1d7e3-0f2b-4a9c-8d6e-1b3f5a7c9e20","from_wallet":"wallet1","currency":"USD","total_amount":100,`+
+			`"legs":[{"to_wallet":"wallet2","amount":100,"transfer_id":"transfer1"}],"created_at":"2022-01-02T03:04:05Z"}`, rr.Body.String())
+	})
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/currency"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// getWalletResponse contains ledger balance (balance) and the part of it which isn't held (available_balance),
+// available balance below zero is covered by credit within overdraft limit
+type getWalletResponse struct {
+	WalletID                  types.WalletID     `json:"wallet_id"`
+	Currency                  string             `json:"currency"`
+	Status                    types.WalletStatus `json:"status"`
+	Balance                   types.Money        `json:"balance"`
+	BalanceFormatted          string             `json:"balance_formatted"`
+	AvailableBalance          types.Money        `json:"available_balance"`
+	AvailableBalanceFormatted string             `json:"available_balance_formatted"`
+	OverdraftLimit            types.Money        `json:"overdraft_limit"`
+	UsedCredit                types.Money        `json:"used_credit"`
+	RemainingCredit           types.Money        `json:"remaining_credit"`
+	CreatedAt                 time.Time          `json:"created_at"`
+	OperationsCount           int                `json:"operations_count"`
+	LastOperationAt           *time.Time         `json:"last_operation_at"`
+}
+
+func (h *Handler) HandleGetWallet(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	walletID := params.ByName("wallet")
+	if walletID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("empty wallet id"))
+		return
+	}
+
+	wallet, err := h.s.Wallet(r.Context(), types.WalletID(walletID))
+	if err != nil {
+		if errors.Cause(err) == types.ErrWalletNotFound {
+			writeErrorResponse(w, http.StatusNotFound, walletNotFoundError(err))
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "fetch wallet"))
+		return
+	}
+
+	available, err := wallet.AvailableBalance()
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "calculate available balance"))
+		return
+	}
+	usedCredit, err := wallet.UsedCredit()
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "calculate used credit"))
+		return
+	}
+	remainingCredit, err := wallet.RemainingCredit()
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "calculate remaining credit"))
+		return
+	}
+
+	resp, err := json.Marshal(&getWalletResponse{
+		WalletID:                  wallet.ID,
+		Currency:                  wallet.Currency,
+		Status:                    wallet.Status,
+		Balance:                   wallet.Balance,
+		BalanceFormatted:          currency.Format(int64(wallet.Balance), wallet.Currency),
+		AvailableBalance:          available,
+		AvailableBalanceFormatted: currency.Format(int64(available), wallet.Currency),
+		OverdraftLimit:            wallet.OverdraftLimit,
+		UsedCredit:                usedCredit,
+		RemainingCredit:           remainingCredit,
+		CreatedAt:                 wallet.CreatedAt,
+		OperationsCount:           wallet.OperationsCount,
+		LastOperationAt:           wallet.LastOperationAt,
+	})
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "marshal response"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		log.WithError(err).Error("failed to write successful response")
+	}
+}
@@ -0,0 +1,165 @@
+package handlers_test
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetWallet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := []httprouter.Param{
+		{
+			Key:   "wallet",
+			Value: "walletID",
+		},
+	}
+
+	t.Run("validation error - empty wallet", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/wallet", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleGetWallet(rr, req, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"empty wallet id"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - wallet not found", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/wallet/walletID", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Wallet(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(types.Wallet{}, types.ErrWalletNotFound)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleGetWallet(rr, req, params)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, `{"error":"wallet not found"}`, rr.Body.String())
+	})
+
+	t.Run("storage internal error", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/wallet/walletID", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Wallet(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(types.Wallet{}, errors.New("storage error"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleGetWallet(rr, req, params)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, `{"error":"fetch wallet: storage error"}`, rr.Body.String())
+	})
+
+	t.Run("internal error - available balance out of range", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/wallet/walletID", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Wallet(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(types.Wallet{ID: "walletID", Currency: "USD", Balance: math.MinInt64, HeldAmount: 1}, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleGetWallet(rr, req, params)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, `{"error":"calculate available balance: amount is out of range subtract -9223372036854775808 and 1"}`, rr.Body.String())
+	})
+
+	t.Run("happy path - wallet without operations", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/wallet/walletID", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Wallet(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(types.Wallet{
+				ID:        "walletID",
+				Currency:  "USD",
+				Status:    types.WalletStatusActive,
+				CreatedAt: time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC),
+			}, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleGetWallet(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"wallet_id":"walletID","currency":"USD","status":"active","balance":0,"balance_formatted":"0.00$","available_balance":0,"available_balance_formatted":"0.00$","overdraft_limit":0,"used_credit":0,"remaining_credit":0,"created_at":"2030-01-01T10:00:00Z","operations_count":0,"last_operation_at":null}`, rr.Body.String())
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/wallet/walletID", nil)
+		require.NoError(t, err)
+
+		lastOperationAt := time.Date(2030, 1, 2, 12, 30, 0, 0, time.UTC)
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Wallet(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(types.Wallet{
+				ID:              "walletID",
+				Currency:        "EUR",
+				Status:          types.WalletStatusFrozen,
+				Balance:         1155,
+				HeldAmount:      155,
+				CreatedAt:       time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC),
+				OperationsCount: 3,
+				LastOperationAt: &lastOperationAt,
+			}, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleGetWallet(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"wallet_id":"walletID","currency":"EUR","status":"frozen","balance":1155,"balance_formatted":"11.55€","available_balance":1000,"available_balance_formatted":"10.00€","overdraft_limit":0,"used_credit":0,"remaining_credit":0,"created_at":"2030-01-01T10:00:00Z","operations_count":3,"last_operation_at":"2030-01-02T12:30:00Z"}`, rr.Body.String())
+	})
+	t.Run("happy path - overdraft", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/wallet/walletID", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Wallet(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(types.Wallet{
+				ID:             "walletID",
+				Currency:       "USD",
+				Status:         types.WalletStatusActive,
+				Balance:        -300,
+				HeldAmount:     200,
+				OverdraftLimit: 1000,
+				CreatedAt:      time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC),
+			}, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleGetWallet(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"wallet_id":"walletID","currency":"USD","status":"active","balance":-300,"balance_formatted":"-3.00$","available_balance":-500,"available_balance_formatted":"-5.00$","overdraft_limit":1000,"used_credit":500,"remaining_credit":500,"created_at":"2030-01-01T10:00:00Z","operations_count":0,"last_operation_at":null}`, rr.Body.String())
+	})
+}
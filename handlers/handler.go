@@ -4,11 +4,13 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -17,20 +19,69 @@ type walletGenerator interface {
 	Generate() (types.WalletID, error)
 }
 
+// storage returns errors caused by types.ErrWalletNotFound if any wallet of the operation doesn't exist
+// and errors caused by types.ErrWalletFrozen or types.ErrWalletClosed if any wallet of the operation isn't active.
+// Transfers violating transfer limits return *types.LimitExceededError
 type storage interface {
-	// CreateWallet creates new wallet in storage with `wallet` identifier
-	CreateWallet(ctx context.Context, wallet types.WalletID) error
-	// Deposit increases wallet balance by amount value
-	Deposit(ctx context.Context, wallet types.WalletID, amount int) error
-	// Transfer transfers amount value from one wallet to another
-	Transfer(ctx context.Context, fromWallet, toWallet types.WalletID, amount int) error
-	// Operations fetches wallet operations by optional filters - operation type and date range
-	Operations(ctx context.Context, wallet types.WalletID, opType types.OperationType, from, to time.Time) ([]types.DBOperation, error)
+	// CreateWallet creates new wallet in storage with `wallet` identifier and ISO 4217 currency code
+	CreateWallet(ctx context.Context, wallet types.WalletID, currencyCode string) error
+	// Wallet fetches wallet details with balance and operations statistics
+	Wallet(ctx context.Context, wallet types.WalletID) (types.Wallet, error)
+	// Deposit increases wallet balance by amount value, the request with already used idempotency key is applied only once
+	Deposit(ctx context.Context, wallet types.WalletID, amount types.Money, idemKey *types.IdempotencyKey) error
+	// Withdraw decreases wallet balance by amount value within transfer limits of the wallet,
+	// the request with already used idempotency key is applied only once
+	Withdraw(ctx context.Context, wallet types.WalletID, amount types.Money, idemKey *types.IdempotencyKey) error
+	// Transfer transfers amount value from one wallet to another and returns identifier of the transfer,
+	// amount is converted by the effective exchange rate if currencies of wallets differ.
+	// The request with already used idempotency key is applied only once and returns the original transfer identifier
+	Transfer(ctx context.Context, fromWallet, toWallet types.WalletID, amount types.Money, idemKey *types.IdempotencyKey) (types.TransferID, error)
+	// BatchTransfer transfers amounts of all legs from fromWallet in one transaction and returns the batch
+	// with transfer identifiers of the legs, the batch fails as a whole if any leg fails.
+	// The request with already used idempotency key is applied only once and returns the original batch
+	BatchTransfer(ctx context.Context, fromWallet types.WalletID, legs []types.BatchLeg, idemKey *types.IdempotencyKey) (types.TransferBatch, error)
+	// TransferBatch fetches the batch with its legs
+	TransferBatch(ctx context.Context, batchID types.BatchID) (types.TransferBatch, error)
+	// Authorize places a hold on amount of fromWallet balance in favor of toWallet and returns hold identifier,
+	// the request with already used idempotency key is applied only once and returns the original hold identifier
+	Authorize(ctx context.Context, fromWallet, toWallet types.WalletID, amount types.Money, idemKey *types.IdempotencyKey) (types.HoldID, error)
+	// Capture transfers held amount fully (zero amount) or partially and returns identifier of the transfer
+	Capture(ctx context.Context, holdID types.HoldID, amount types.Money) (types.TransferID, error)
+	// Void releases held amount
+	Void(ctx context.Context, holdID types.HoldID) error
+	// Reverse compensates the rest of operation (zero amount) or its part and returns identifiers of reversal operations,
+	// the request with already used idempotency key is applied only once and returns the original identifiers
+	Reverse(ctx context.Context, operationID types.OperationID, amount types.Money, idemKey *types.IdempotencyKey) ([]types.OperationID, error)
+	// Operation fetches operation by its public identifier with the balance of the wallet after it
+	Operation(ctx context.Context, operationID types.OperationID) (types.DBOperation, error)
+	// SetWalletStatus changes wallet status and saves the change with reason to the status history
+	SetWalletStatus(ctx context.Context, wallet types.WalletID, status types.WalletStatus, reason string) error
+	// WalletStatusHistory fetches status changes of wallet from the oldest to the newest one
+	WalletStatusHistory(ctx context.Context, wallet types.WalletID) ([]types.WalletStatusChange, error)
+	// SetOverdraftLimit sets the amount by which wallet balance can go below zero
+	SetOverdraftLimit(ctx context.Context, wallet types.WalletID, limit types.Money) error
+	// SetTransferLimits replaces transfer limits of the wallet or default transfer limits of the currency
+	SetTransferLimits(ctx context.Context, limits types.TransferLimits) error
+	// TransferLimits fetches default transfer limits of currencies followed by transfer limits of wallets
+	TransferLimits(ctx context.Context) ([]types.TransferLimits, error)
+	// CreateSchedule saves active schedule of transfers and returns it with identifier and the next run
+	CreateSchedule(ctx context.Context, schedule types.Schedule) (types.Schedule, error)
+	// WalletSchedules fetches schedules of transfers from the wallet
+	WalletSchedules(ctx context.Context, wallet types.WalletID) ([]types.Schedule, error)
+	// CancelSchedule stops active schedule
+	CancelSchedule(ctx context.Context, scheduleID types.ScheduleID) error
+	// ScheduleRuns fetches executions of the schedule from the oldest to the newest one
+	ScheduleRuns(ctx context.Context, scheduleID types.ScheduleID) ([]types.ScheduleRun, error)
+	// SaveRates saves new versions of exchange rates and returns the number of saved ones
+	SaveRates(ctx context.Context, rates []types.FXRate) (int64, error)
+	// Operations opens rows of a page of wallet operations by optional filters - operation type and date range,
+	// the page has balances of the wallet at the boundaries of the date range
+	Operations(ctx context.Context, wallet types.WalletID, filter types.OperationsFilter) (types.OperationsPage, error)
 }
 
 type exporter interface {
-	// Export exports operations in the specified format
-	Export(format types.ExportFormat, ops []types.ExportOperation) ([]byte, error)
+	// Export writes page of operations to w in the specified format
+	Export(w io.Writer, format types.ExportFormat, page types.ExportPage) error
 }
 
 type Handler struct {
@@ -57,6 +108,64 @@ func writeErrorResponse(w http.ResponseWriter, code int, err error) {
 	}
 }
 
+// walletNotFoundError returns the error which points to the missing wallet if err contains such one
+func walletNotFoundError(err error) error {
+	var notFoundErr *types.WalletNotFoundError
+	if errors.As(err, &notFoundErr) {
+		return notFoundErr
+	}
+	return types.ErrWalletNotFound
+}
+
+// walletStatusError returns the error which points to the inactive wallet if err contains such one
+func walletStatusError(err error) error {
+	var statusErr *types.WalletStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr
+	}
+	return errors.Cause(err)
+}
+
+type limitExceededResponse struct {
+	Error     string `json:"error"`
+	Limit     string `json:"limit"`
+	Remaining int64  `json:"remaining"`
+}
+
+// writeLimitExceededResponse writes the violated limit and the remaining allowance along with the error
+func writeLimitExceededResponse(w http.ResponseWriter, err error) {
+	var limitErr *types.LimitExceededError
+	if !errors.As(err, &limitErr) {
+		writeErrorResponse(w, http.StatusUnprocessableEntity, errors.Cause(err))
+		return
+	}
+
+	log.WithError(err).Error("handler error")
+
+	resp, err := json.Marshal(&limitExceededResponse{
+		Error:     types.ErrLimitExceeded.Error(),
+		Limit:     limitErr.Limit,
+		Remaining: limitErr.Remaining,
+	})
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "marshal response"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	if _, err = w.Write(resp); err != nil {
+		log.WithError(err).Error("failed to write erroneous response")
+	}
+}
+
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// errorResponse encodes the error as JSON, so quotes of user input in the message are escaped
 func errorResponse(err error) []byte {
-	return []byte(`{"error":"` + strings.TrimSpace(err.Error()) + `"}`)
+	// marshalling of the string field can't fail
+	resp, _ := json.Marshal(&errorBody{Error: strings.TrimSpace(err.Error())})
+	return resp
 }
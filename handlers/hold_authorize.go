@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type authorizeHoldResponse struct {
+	HoldID types.HoldID `json:"hold_id"`
+}
+
+// HandleAuthorizeHold places a hold, its payload is the same as transfer one
+func (h *Handler) HandleAuthorizeHold(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var authorizeReq transferRequest
+	if err := json.NewDecoder(r.Body).Decode(&authorizeReq); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "decode request"))
+		return
+	}
+
+	if err := h.validateTransferRequest(authorizeReq); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if authorizeReq.DecimalAmount != "" {
+		amount, ok := h.decimalAmount(w, r, authorizeReq.FromWallet, "from_wallet", authorizeReq.DecimalAmount)
+		if !ok {
+			return
+		}
+		authorizeReq.Amount, authorizeReq.DecimalAmount = amount, ""
+	}
+
+	idemKey, err := idempotencyKey(r, "hold", authorizeReq)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	holdID, err := h.s.Authorize(r.Context(), authorizeReq.FromWallet, authorizeReq.ToWallet, authorizeReq.Amount, idemKey)
+	if err != nil {
+		switch errors.Cause(err) {
+		case types.ErrWalletNotFound:
+			writeErrorResponse(w, http.StatusNotFound, walletNotFoundError(err))
+			return
+		case types.ErrWalletFrozen:
+			writeErrorResponse(w, http.StatusForbidden, walletStatusError(err))
+			return
+		case types.ErrWalletClosed:
+			writeErrorResponse(w, http.StatusConflict, walletStatusError(err))
+			return
+		case types.ErrUnavailableBalance:
+			writeErrorResponse(w, http.StatusBadRequest, types.ErrUnavailableBalance)
+			return
+		case types.ErrAmountOverflow:
+			writeErrorResponse(w, http.StatusBadRequest, types.ErrAmountOverflow)
+			return
+		case types.ErrLimitExceeded:
+			writeLimitExceededResponse(w, err)
+			return
+		case types.ErrIdempotencyKeyReused:
+			writeErrorResponse(w, http.StatusConflict, types.ErrIdempotencyKeyReused)
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "save to storage"))
+		return
+	}
+
+	resp, err := json.Marshal(&authorizeHoldResponse{HoldID: holdID})
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "marshal response"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		log.WithError(err).Error("failed to write successful response")
+	}
+}
@@ -0,0 +1,105 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleAuthorizeHold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("validation error - similar wallets", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet1","amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/holds", body)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleAuthorizeHold(rr, req, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"similar wallets provided"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - insufficient funds in the account", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/holds", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Authorize(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), types.Money(100), nil).
+			Times(1).
+			Return(types.HoldID(""), errors.Wrap(types.ErrUnavailableBalance, "rolled back"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleAuthorizeHold(rr, req, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"insufficient funds in the account"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - wallet not found", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/holds", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Authorize(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), types.Money(100), nil).
+			Times(1).
+			Return(types.HoldID(""), &types.WalletNotFoundError{Field: "to_wallet"})
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleAuthorizeHold(rr, req, nil)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, `{"error":"to_wallet not found"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - limit exceeded", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/holds", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Authorize(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), types.Money(100), nil).
+			Times(1).
+			Return(types.HoldID(""), errors.Wrap(&types.LimitExceededError{Limit: types.LimitMaxAmount, Remaining: 50}, "rolled back"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleAuthorizeHold(rr, req, nil)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Equal(t, `{"error":"transfer limit exceeded","limit":"max_amount","remaining":50}`, rr.Body.String())
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/holds", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Authorize(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), types.Money(100), nil).
+			Times(1).
+			Return(types.HoldID("holdID"), nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleAuthorizeHold(rr, req, nil)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"hold_id":"holdID"}`, rr.Body.String())
+	})
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type captureHoldRequest struct {
+	Amount types.Money `json:"amount"`
+}
+
+func (h *Handler) HandleCaptureHold(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	holdID := types.HoldID(params.ByName("id"))
+	if !holdID.Valid() {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("invalid hold id"))
+		return
+	}
+
+	// empty body means capture of the whole held amount
+	var captureReq captureHoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&captureReq); err != nil && err != io.EOF {
+		writeErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "decode request"))
+		return
+	}
+
+	if captureReq.Amount < 0 {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("invalid amount"))
+		return
+	}
+
+	transferID, err := h.s.Capture(r.Context(), holdID, captureReq.Amount)
+	if err != nil {
+		switch cause := errors.Cause(err); cause {
+		case types.ErrHoldNotFound:
+			writeErrorResponse(w, http.StatusNotFound, cause)
+			return
+		case types.ErrWalletNotFound:
+			writeErrorResponse(w, http.StatusNotFound, walletNotFoundError(err))
+			return
+		case types.ErrWalletFrozen:
+			writeErrorResponse(w, http.StatusForbidden, walletStatusError(err))
+			return
+		case types.ErrWalletClosed:
+			writeErrorResponse(w, http.StatusConflict, walletStatusError(err))
+			return
+		case types.ErrInvalidCaptureAmount, types.ErrUnavailableBalance, types.ErrFXRateNotFound, types.ErrConvertedAmountTooSmall, types.ErrAmountOverflow:
+			writeErrorResponse(w, http.StatusBadRequest, cause)
+			return
+		case types.ErrLimitExceeded:
+			writeLimitExceededResponse(w, err)
+			return
+		case types.ErrHoldNotActive, types.ErrHoldExpired:
+			writeErrorResponse(w, http.StatusConflict, cause)
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "save to storage"))
+		return
+	}
+
+	resp, err := json.Marshal(&transferResponse{TransferID: transferID})
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "marshal response"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		log.WithError(err).Error("failed to write successful response")
+	}
+}
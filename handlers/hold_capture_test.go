@@ -0,0 +1,129 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCaptureHold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := []httprouter.Param{
+		{
+			Key:   "id",
+			Value: "3b9e2f4a-6c1d-4e8f-9a7b-5d2c0e1f8a64",
+		},
+	}
+
+	t.Run("validation error - invalid hold id", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/holds/holdID/capture", bytes.NewReader([]byte(`{"amount": 50}`)))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleCaptureHold(rr, req, []httprouter.Param{{Key: "id", Value: "holdID"}})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"invalid hold id"}`, rr.Body.String())
+	})
+
+	t.Run("validation error - invalid amount", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/holds/3b9e2f4a-6c1d-4e8f-9a7b-5d2c0e1f8a64/capture", bytes.NewReader([]byte(`{"amount": -1}`)))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleCaptureHold(rr, req, params)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"invalid amount"}`, rr.Body.String())
+	})
+
+	storageErrors := []struct {
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			err:          types.ErrHoldNotFound,
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"error":"hold not found"}`,
+		},
+		{
+			err:          types.ErrInvalidCaptureAmount,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"capture amount exceeds held amount"}`,
+		},
+		{
+			err:          types.ErrHoldNotActive,
+			expectedCode: http.StatusConflict,
+			expectedBody: `{"error":"hold is already captured or voided"}`,
+		},
+		{
+			err:          types.ErrHoldExpired,
+			expectedCode: http.StatusConflict,
+			expectedBody: `{"error":"hold is expired"}`,
+		},
+		{
+			err:          &types.WalletNotFoundError{Field: "to_wallet"},
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"error":"to_wallet not found"}`,
+		},
+		{
+			err:          types.ErrUnavailableBalance,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"insufficient funds in the account"}`,
+		},
+		{
+			err:          &types.LimitExceededError{Limit: types.LimitMaxDailyAmount, Remaining: 20},
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: `{"error":"transfer limit exceeded","limit":"max_daily_amount","remaining":20}`,
+		},
+	}
+
+	for _, c := range storageErrors {
+		t.Run("storage error - "+c.err.Error(), func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/holds/3b9e2f4a-6c1d-4e8f-9a7b-5d2c0e1f8a64/capture", bytes.NewReader([]byte(`{"amount": 50}`)))
+			require.NoError(t, err)
+
+			storageMock := mocks.NewMockstorage(ctrl)
+			storageMock.EXPECT().
+				Capture(gomock.Any(), types.HoldID("3b9e2f4a-6c1d-4e8f-9a7b-5d2c0e1f8a64"), types.Money(50)).
+				Times(1).
+				Return(types.TransferID(""), errors.Wrap(c.err, "rolled back"))
+
+			rr := httptest.NewRecorder()
+			handlers.New(nil, storageMock, nil).HandleCaptureHold(rr, req, params)
+
+			assert.Equal(t, c.expectedCode, rr.Code)
+			assert.Equal(t, c.expectedBody, rr.Body.String())
+		})
+	}
+
+	t.Run("happy path - capture of the whole held amount", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/holds/3b9e2f4a-6c1d-4e8f-9a7b-5d2c0e1f8a64/capture", bytes.NewReader(nil))
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Capture(gomock.Any(), types.HoldID("3b9e2f4a-6c1d-4e8f-9a7b-5d2c0e1f8a64"), types.Money(0)).
+			Times(1).
+			Return(types.TransferID("transferID"), nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleCaptureHold(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"transfer_id":"transferID"}`, rr.Body.String())
+	})
+}
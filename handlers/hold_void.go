@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+func (h *Handler) HandleVoidHold(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	holdID := types.HoldID(params.ByName("id"))
+	if !holdID.Valid() {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("invalid hold id"))
+		return
+	}
+
+	if err := h.s.Void(r.Context(), holdID); err != nil {
+		switch cause := errors.Cause(err); cause {
+		case types.ErrHoldNotFound:
+			writeErrorResponse(w, http.StatusNotFound, cause)
+			return
+		case types.ErrHoldNotActive, types.ErrHoldExpired:
+			writeErrorResponse(w, http.StatusConflict, cause)
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "save to storage"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+}
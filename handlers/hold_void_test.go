@@ -0,0 +1,72 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleVoidHold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := []httprouter.Param{
+		{
+			Key:   "id",
+			Value: "3b9e2f4a-6c1d-4e8f-9a7b-5d2c0e1f8a64",
+		},
+	}
+
+	t.Run("validation error - invalid hold id", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/holds/holdID/void", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleVoidHold(rr, req, []httprouter.Param{{Key: "id", Value: "holdID"}})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"invalid hold id"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - hold is not active", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/holds/3b9e2f4a-6c1d-4e8f-9a7b-5d2c0e1f8a64/void", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Void(gomock.Any(), types.HoldID("3b9e2f4a-6c1d-4e8f-9a7b-5d2c0e1f8a64")).
+			Times(1).
+			Return(errors.Wrap(types.ErrHoldNotActive, "rolled back"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleVoidHold(rr, req, params)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Equal(t, `{"error":"hold is already captured or voided"}`, rr.Body.String())
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/holds/3b9e2f4a-6c1d-4e8f-9a7b-5d2c0e1f8a64/void", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Void(gomock.Any(), types.HoldID("3b9e2f4a-6c1d-4e8f-9a7b-5d2c0e1f8a64")).
+			Times(1).
+			Return(nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleVoidHold(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
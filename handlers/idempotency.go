@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+)
+
+// idempotencyKey builds idempotency key from the request header, nil is returned if the header is not provided.
+// Request hash is calculated from operation scope and decoded payload, so formatting of the body doesn't affect it.
+func idempotencyKey(r *http.Request, scope string, payload interface{}) (*types.IdempotencyKey, error) {
+	key := r.Header.Get(idempotencyKeyHeader)
+	if key == "" {
+		return nil, nil
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, errors.New("idempotency key is too long")
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal idempotent request")
+	}
+
+	hash := sha256.Sum256(append([]byte(scope+":"), data...))
+
+	return &types.IdempotencyKey{
+		Key:         key,
+		RequestHash: hex.EncodeToString(hash[:]),
+	}, nil
+}
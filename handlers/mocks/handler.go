@@ -6,8 +6,8 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
-	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	types "github.com/justteddy/wallet/types"
@@ -74,61 +74,341 @@ func (m *Mockstorage) EXPECT() *MockstorageMockRecorder {
 	return m.recorder
 }
 
+// Authorize mocks base method.
+func (m *Mockstorage) Authorize(ctx context.Context, fromWallet, toWallet types.WalletID, amount types.Money, idemKey *types.IdempotencyKey) (types.HoldID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, fromWallet, toWallet, amount, idemKey)
+	ret0, _ := ret[0].(types.HoldID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockstorageMockRecorder) Authorize(ctx, fromWallet, toWallet, amount, idemKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*Mockstorage)(nil).Authorize), ctx, fromWallet, toWallet, amount, idemKey)
+}
+
+// BatchTransfer mocks base method.
+func (m *Mockstorage) BatchTransfer(ctx context.Context, fromWallet types.WalletID, legs []types.BatchLeg, idemKey *types.IdempotencyKey) (types.TransferBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchTransfer", ctx, fromWallet, legs, idemKey)
+	ret0, _ := ret[0].(types.TransferBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchTransfer indicates an expected call of BatchTransfer.
+func (mr *MockstorageMockRecorder) BatchTransfer(ctx, fromWallet, legs, idemKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchTransfer", reflect.TypeOf((*Mockstorage)(nil).BatchTransfer), ctx, fromWallet, legs, idemKey)
+}
+
+// CancelSchedule mocks base method.
+func (m *Mockstorage) CancelSchedule(ctx context.Context, scheduleID types.ScheduleID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelSchedule", ctx, scheduleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelSchedule indicates an expected call of CancelSchedule.
+func (mr *MockstorageMockRecorder) CancelSchedule(ctx, scheduleID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelSchedule", reflect.TypeOf((*Mockstorage)(nil).CancelSchedule), ctx, scheduleID)
+}
+
+// Capture mocks base method.
+func (m *Mockstorage) Capture(ctx context.Context, holdID types.HoldID, amount types.Money) (types.TransferID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capture", ctx, holdID, amount)
+	ret0, _ := ret[0].(types.TransferID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Capture indicates an expected call of Capture.
+func (mr *MockstorageMockRecorder) Capture(ctx, holdID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*Mockstorage)(nil).Capture), ctx, holdID, amount)
+}
+
+// CreateSchedule mocks base method.
+func (m *Mockstorage) CreateSchedule(ctx context.Context, schedule types.Schedule) (types.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSchedule", ctx, schedule)
+	ret0, _ := ret[0].(types.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSchedule indicates an expected call of CreateSchedule.
+func (mr *MockstorageMockRecorder) CreateSchedule(ctx, schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSchedule", reflect.TypeOf((*Mockstorage)(nil).CreateSchedule), ctx, schedule)
+}
+
 // CreateWallet mocks base method.
-func (m *Mockstorage) CreateWallet(ctx context.Context, wallet types.WalletID) error {
+func (m *Mockstorage) CreateWallet(ctx context.Context, wallet types.WalletID, currencyCode string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateWallet", ctx, wallet)
+	ret := m.ctrl.Call(m, "CreateWallet", ctx, wallet, currencyCode)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateWallet indicates an expected call of CreateWallet.
-func (mr *MockstorageMockRecorder) CreateWallet(ctx, wallet interface{}) *gomock.Call {
+func (mr *MockstorageMockRecorder) CreateWallet(ctx, wallet, currencyCode interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*Mockstorage)(nil).CreateWallet), ctx, wallet)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*Mockstorage)(nil).CreateWallet), ctx, wallet, currencyCode)
 }
 
 // Deposit mocks base method.
-func (m *Mockstorage) Deposit(ctx context.Context, wallet types.WalletID, amount int) error {
+func (m *Mockstorage) Deposit(ctx context.Context, wallet types.WalletID, amount types.Money, idemKey *types.IdempotencyKey) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Deposit", ctx, wallet, amount)
+	ret := m.ctrl.Call(m, "Deposit", ctx, wallet, amount, idemKey)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Deposit indicates an expected call of Deposit.
-func (mr *MockstorageMockRecorder) Deposit(ctx, wallet, amount interface{}) *gomock.Call {
+func (mr *MockstorageMockRecorder) Deposit(ctx, wallet, amount, idemKey interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*Mockstorage)(nil).Deposit), ctx, wallet, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*Mockstorage)(nil).Deposit), ctx, wallet, amount, idemKey)
+}
+
+// Operation mocks base method.
+func (m *Mockstorage) Operation(ctx context.Context, operationID types.OperationID) (types.DBOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Operation", ctx, operationID)
+	ret0, _ := ret[0].(types.DBOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Operation indicates an expected call of Operation.
+func (mr *MockstorageMockRecorder) Operation(ctx, operationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Operation", reflect.TypeOf((*Mockstorage)(nil).Operation), ctx, operationID)
 }
 
 // Operations mocks base method.
-func (m *Mockstorage) Operations(ctx context.Context, wallet types.WalletID, opType types.OperationType, from, to time.Time) ([]types.DBOperation, error) {
+func (m *Mockstorage) Operations(ctx context.Context, wallet types.WalletID, filter types.OperationsFilter) (types.OperationsPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Operations", ctx, wallet, opType, from, to)
-	ret0, _ := ret[0].([]types.DBOperation)
+	ret := m.ctrl.Call(m, "Operations", ctx, wallet, filter)
+	ret0, _ := ret[0].(types.OperationsPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Operations indicates an expected call of Operations.
-func (mr *MockstorageMockRecorder) Operations(ctx, wallet, opType, from, to interface{}) *gomock.Call {
+func (mr *MockstorageMockRecorder) Operations(ctx, wallet, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Operations", reflect.TypeOf((*Mockstorage)(nil).Operations), ctx, wallet, opType, from, to)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Operations", reflect.TypeOf((*Mockstorage)(nil).Operations), ctx, wallet, filter)
 }
 
-// Transfer mocks base method.
-func (m *Mockstorage) Transfer(ctx context.Context, fromWallet, toWallet types.WalletID, amount int) error {
+// Reverse mocks base method.
+func (m *Mockstorage) Reverse(ctx context.Context, operationID types.OperationID, amount types.Money, idemKey *types.IdempotencyKey) ([]types.OperationID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reverse", ctx, operationID, amount, idemKey)
+	ret0, _ := ret[0].([]types.OperationID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reverse indicates an expected call of Reverse.
+func (mr *MockstorageMockRecorder) Reverse(ctx, operationID, amount, idemKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reverse", reflect.TypeOf((*Mockstorage)(nil).Reverse), ctx, operationID, amount, idemKey)
+}
+
+// SaveRates mocks base method.
+func (m *Mockstorage) SaveRates(ctx context.Context, rates []types.FXRate) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRates", ctx, rates)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveRates indicates an expected call of SaveRates.
+func (mr *MockstorageMockRecorder) SaveRates(ctx, rates interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRates", reflect.TypeOf((*Mockstorage)(nil).SaveRates), ctx, rates)
+}
+
+// ScheduleRuns mocks base method.
+func (m *Mockstorage) ScheduleRuns(ctx context.Context, scheduleID types.ScheduleID) ([]types.ScheduleRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleRuns", ctx, scheduleID)
+	ret0, _ := ret[0].([]types.ScheduleRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScheduleRuns indicates an expected call of ScheduleRuns.
+func (mr *MockstorageMockRecorder) ScheduleRuns(ctx, scheduleID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleRuns", reflect.TypeOf((*Mockstorage)(nil).ScheduleRuns), ctx, scheduleID)
+}
+
+// SetOverdraftLimit mocks base method.
+func (m *Mockstorage) SetOverdraftLimit(ctx context.Context, wallet types.WalletID, limit types.Money) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOverdraftLimit", ctx, wallet, limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetOverdraftLimit indicates an expected call of SetOverdraftLimit.
+func (mr *MockstorageMockRecorder) SetOverdraftLimit(ctx, wallet, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOverdraftLimit", reflect.TypeOf((*Mockstorage)(nil).SetOverdraftLimit), ctx, wallet, limit)
+}
+
+// SetTransferLimits mocks base method.
+func (m *Mockstorage) SetTransferLimits(ctx context.Context, limits types.TransferLimits) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTransferLimits", ctx, limits)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTransferLimits indicates an expected call of SetTransferLimits.
+func (mr *MockstorageMockRecorder) SetTransferLimits(ctx, limits interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTransferLimits", reflect.TypeOf((*Mockstorage)(nil).SetTransferLimits), ctx, limits)
+}
+
+// SetWalletStatus mocks base method.
+func (m *Mockstorage) SetWalletStatus(ctx context.Context, wallet types.WalletID, status types.WalletStatus, reason string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Transfer", ctx, fromWallet, toWallet, amount)
+	ret := m.ctrl.Call(m, "SetWalletStatus", ctx, wallet, status, reason)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// SetWalletStatus indicates an expected call of SetWalletStatus.
+func (mr *MockstorageMockRecorder) SetWalletStatus(ctx, wallet, status, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletStatus", reflect.TypeOf((*Mockstorage)(nil).SetWalletStatus), ctx, wallet, status, reason)
+}
+
+// Transfer mocks base method.
+func (m *Mockstorage) Transfer(ctx context.Context, fromWallet, toWallet types.WalletID, amount types.Money, idemKey *types.IdempotencyKey) (types.TransferID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfer", ctx, fromWallet, toWallet, amount, idemKey)
+	ret0, _ := ret[0].(types.TransferID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // Transfer indicates an expected call of Transfer.
-func (mr *MockstorageMockRecorder) Transfer(ctx, fromWallet, toWallet, amount interface{}) *gomock.Call {
+func (mr *MockstorageMockRecorder) Transfer(ctx, fromWallet, toWallet, amount, idemKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*Mockstorage)(nil).Transfer), ctx, fromWallet, toWallet, amount, idemKey)
+}
+
+// TransferBatch mocks base method.
+func (m *Mockstorage) TransferBatch(ctx context.Context, batchID types.BatchID) (types.TransferBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferBatch", ctx, batchID)
+	ret0, _ := ret[0].(types.TransferBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferBatch indicates an expected call of TransferBatch.
+func (mr *MockstorageMockRecorder) TransferBatch(ctx, batchID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferBatch", reflect.TypeOf((*Mockstorage)(nil).TransferBatch), ctx, batchID)
+}
+
+// TransferLimits mocks base method.
+func (m *Mockstorage) TransferLimits(ctx context.Context) ([]types.TransferLimits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferLimits", ctx)
+	ret0, _ := ret[0].([]types.TransferLimits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferLimits indicates an expected call of TransferLimits.
+func (mr *MockstorageMockRecorder) TransferLimits(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*Mockstorage)(nil).Transfer), ctx, fromWallet, toWallet, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferLimits", reflect.TypeOf((*Mockstorage)(nil).TransferLimits), ctx)
+}
+
+// Void mocks base method.
+func (m *Mockstorage) Void(ctx context.Context, holdID types.HoldID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Void", ctx, holdID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Void indicates an expected call of Void.
+func (mr *MockstorageMockRecorder) Void(ctx, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Void", reflect.TypeOf((*Mockstorage)(nil).Void), ctx, holdID)
+}
+
+// Wallet mocks base method.
+func (m *Mockstorage) Wallet(ctx context.Context, wallet types.WalletID) (types.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Wallet", ctx, wallet)
+	ret0, _ := ret[0].(types.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Wallet indicates an expected call of Wallet.
+func (mr *MockstorageMockRecorder) Wallet(ctx, wallet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Wallet", reflect.TypeOf((*Mockstorage)(nil).Wallet), ctx, wallet)
+}
+
+// WalletSchedules mocks base method.
+func (m *Mockstorage) WalletSchedules(ctx context.Context, wallet types.WalletID) ([]types.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalletSchedules", ctx, wallet)
+	ret0, _ := ret[0].([]types.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WalletSchedules indicates an expected call of WalletSchedules.
+func (mr *MockstorageMockRecorder) WalletSchedules(ctx, wallet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalletSchedules", reflect.TypeOf((*Mockstorage)(nil).WalletSchedules), ctx, wallet)
+}
+
+// WalletStatusHistory mocks base method.
+func (m *Mockstorage) WalletStatusHistory(ctx context.Context, wallet types.WalletID) ([]types.WalletStatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalletStatusHistory", ctx, wallet)
+	ret0, _ := ret[0].([]types.WalletStatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WalletStatusHistory indicates an expected call of WalletStatusHistory.
+func (mr *MockstorageMockRecorder) WalletStatusHistory(ctx, wallet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalletStatusHistory", reflect.TypeOf((*Mockstorage)(nil).WalletStatusHistory), ctx, wallet)
+}
+
+// Withdraw mocks base method.
+func (m *Mockstorage) Withdraw(ctx context.Context, wallet types.WalletID, amount types.Money, idemKey *types.IdempotencyKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Withdraw", ctx, wallet, amount, idemKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Withdraw indicates an expected call of Withdraw.
+func (mr *MockstorageMockRecorder) Withdraw(ctx, wallet, amount, idemKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*Mockstorage)(nil).Withdraw), ctx, wallet, amount, idemKey)
 }
 
 // Mockexporter is a mock of exporter interface.
@@ -155,16 +435,15 @@ func (m *Mockexporter) EXPECT() *MockexporterMockRecorder {
 }
 
 // Export mocks base method.
-func (m *Mockexporter) Export(format types.ExportFormat, ops []types.ExportOperation) ([]byte, error) {
+func (m *Mockexporter) Export(w io.Writer, format types.ExportFormat, page types.ExportPage) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Export", format, ops)
-	ret0, _ := ret[0].([]byte)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "Export", w, format, page)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // Export indicates an expected call of Export.
-func (mr *MockexporterMockRecorder) Export(format, ops interface{}) *gomock.Call {
+func (mr *MockexporterMockRecorder) Export(w, format, page interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*Mockexporter)(nil).Export), format, ops)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*Mockexporter)(nil).Export), w, format, page)
 }
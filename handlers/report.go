@@ -1,20 +1,35 @@
 package handlers
 
 import (
+	"bufio"
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/currency"
 	"github.com/justteddy/wallet/types"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	defaultReportLimit = 1000
+	maxReportLimit     = 10000
+
+	// report is sent to the client in chunks of this size
+	reportChunkSize = 32 * 1024
+)
+
 type reportRequest struct {
 	FromDate      string              `json:"from_date"`
 	ToDate        string              `json:"to_date"`
 	OperationType types.OperationType `json:"operation_type"`
+	Locale        string              `json:"locale"`
+	Timezone      string              `json:"timezone"`
+	Limit         int                 `json:"limit"`
+	Cursor        string              `json:"cursor"`
 }
 
 func (h *Handler) HandleReport(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
@@ -27,72 +42,184 @@ func (h *Handler) HandleReport(w http.ResponseWriter, r *http.Request, params ht
 		return
 	}
 
-	fromDate, toDate, err := h.validateReportRequest(format, walletID, reportReq)
+	// cursor of the Link header comes in the query string
+	if reportReq.Cursor == "" {
+		reportReq.Cursor = r.URL.Query().Get("cursor")
+	}
+
+	filter, loc, err := h.validateReportRequest(format, walletID, reportReq)
 	if err != nil {
 		writeErrorResponse(w, http.StatusBadRequest, err)
 		return
 	}
 
-	ops, err := h.s.Operations(r.Context(), types.WalletID(walletID), reportReq.OperationType, fromDate, toDate)
+	formatOpts := currency.DefaultOptions
+	if reportReq.Locale != "" {
+		var ok bool
+		if formatOpts, ok = currency.Locale(reportReq.Locale); !ok {
+			writeErrorResponse(w, http.StatusBadRequest, errors.New("unsupported locale"))
+			return
+		}
+	}
+
+	ops, err := h.s.Operations(r.Context(), types.WalletID(walletID), filter)
 	if err != nil {
+		if errors.Cause(err) == types.ErrWalletNotFound {
+			writeErrorResponse(w, http.StatusNotFound, walletNotFoundError(err))
+			return
+		}
 		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "fetch operations"))
 		return
 	}
+	defer func() {
+		if err := ops.Rows.Close(); err != nil {
+			log.WithError(err).Error("failed to close operation rows")
+		}
+	}()
+
+	page := types.ExportPage{
+		Operations:     types.ExportRows(ops.Rows, formatOpts, loc),
+		FirstPage:      filter.Cursor == nil,
+		OpeningBalance: types.ExportBalance(types.ExportRowOpeningBalance, types.WalletID(walletID), ops.OpeningBalance, ops.Currency, filter.From, formatOpts, loc),
+		ClosingBalance: types.ExportBalance(types.ExportRowClosingBalance, types.WalletID(walletID), ops.ClosingBalance, ops.Currency, filter.To, formatOpts, loc),
+	}
+	if ops.NextCursor != nil {
+		page.NextCursor = ops.NextCursor.String()
+	}
+
+	rw := &reportWriter{w: w, header: http.Header{}}
+	switch types.ExportFormat(format) {
+	case types.ExportFormatJSON:
+		rw.header.Add("Content-Type", "application/json")
+	case types.ExportFormatCSV:
+		if page.NextCursor != "" {
+			rw.header.Add("Link", nextPageLink(r.URL, page.NextCursor))
+		}
+	}
 
-	data, err := h.e.Export(types.ExportFormat(format), types.TransformDBToExportOperation(ops))
+	buf := bufio.NewWriterSize(rw, reportChunkSize)
+	err = h.e.Export(buf, types.ExportFormat(format), page)
+	if err == nil {
+		err = buf.Flush()
+	}
 	if err != nil {
-		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "export operations"))
-		return
+		if !rw.committed {
+			writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "export operations"))
+			return
+		}
+		// the client must not take the partially sent report for a complete one, so the response is aborted
+		log.WithError(err).Error("failed to write report")
+		panic(http.ErrAbortHandler)
 	}
 
-	if format == string(types.ExportFormatJSON) {
-		w.Header().Add("Content-Type", "application/json")
+	rw.commit()
+}
+
+// reportWriter sends the report with chunked transfer encoding, every chunk is flushed to the client immediately.
+// The response is committed by the first chunk, so errors which happen before it get usual erroneous responses.
+type reportWriter struct {
+	w         http.ResponseWriter
+	header    http.Header
+	committed bool
+}
+
+func (rw *reportWriter) commit() {
+	if rw.committed {
+		return
+	}
+	for key, values := range rw.header {
+		rw.w.Header()[key] = values
 	}
+	rw.w.WriteHeader(http.StatusOK)
+	rw.committed = true
+}
 
-	w.WriteHeader(http.StatusOK)
-	if _, err := w.Write(data); err != nil {
-		log.WithError(err).Error("failed to write successful response")
+func (rw *reportWriter) Write(p []byte) (int, error) {
+	rw.commit()
+	n, err := rw.w.Write(p)
+	if flusher, ok := rw.w.(http.Flusher); ok {
+		flusher.Flush()
 	}
+	return n, err
+}
+
+// nextPageLink returns Link header value which points to the same report starting from cursor,
+// the request body should be repeated to follow the link
+func nextPageLink(u *url.URL, cursor string) string {
+	query := u.Query()
+	query.Set("cursor", cursor)
+	next := url.URL{Path: u.Path, RawQuery: query.Encode()}
+	return "<" + next.String() + `>; rel="next"`
 }
 
-func (h *Handler) validateReportRequest(format, walletID string, reportReq reportRequest) (time.Time, time.Time, error) {
-	var fromDate, toDate time.Time
+// validateReportRequest returns filter of operations and location of the report timezone,
+// dates of the request are days in that timezone
+func (h *Handler) validateReportRequest(format, walletID string, reportReq reportRequest) (types.OperationsFilter, *time.Location, error) {
+	filter := types.OperationsFilter{Type: reportReq.OperationType, Limit: defaultReportLimit}
+	loc := time.UTC
 
 	if walletID == "" {
-		return fromDate, toDate, errors.New("empty wallet id")
+		return filter, loc, errors.New("empty wallet id")
 	}
 	if format == "" {
-		return fromDate, toDate, errors.New("empty format")
+		return filter, loc, errors.New("empty format")
 	}
 
 	if _, ok := types.AllExportFormats[types.ExportFormat(format)]; !ok {
-		return fromDate, toDate, errors.New("unexpected export format")
+		return filter, loc, errors.New("unexpected export format")
 	}
 
 	if reportReq.OperationType != "" {
 		if _, ok := types.AllOperationTypes[reportReq.OperationType]; !ok {
-			return fromDate, toDate, errors.New("unexpected operation type")
+			return filter, loc, errors.New("unexpected operation type")
 		}
 	}
 
 	var err error
+	if reportReq.Timezone != "" {
+		// Local is the timezone of the server, it's not a user's one
+		if loc, err = time.LoadLocation(reportReq.Timezone); err != nil || reportReq.Timezone == "Local" {
+			return filter, loc, errors.New("unsupported timezone")
+		}
+	}
+
 	if reportReq.FromDate != "" {
-		if fromDate, err = time.Parse(types.DateLayout, reportReq.FromDate); err != nil {
-			return fromDate, toDate, errors.Wrap(err, "invalid date format in from_date, should be YYYY-MM-DD")
+		if filter.From, err = time.ParseInLocation(types.DateLayout, reportReq.FromDate, loc); err != nil {
+			return filter, loc, errors.Wrap(err, "invalid date format in from_date, should be YYYY-MM-DD")
 		}
 	}
 
 	if reportReq.ToDate != "" {
-		if toDate, err = time.Parse(types.DateLayout, reportReq.ToDate); err != nil {
-			return fromDate, toDate, errors.Wrap(err, "invalid date format in to_date, should be YYYY-MM-DD")
+		if filter.To, err = time.ParseInLocation(types.DateLayout, reportReq.ToDate, loc); err != nil {
+			return filter, loc, errors.Wrap(err, "invalid date format in to_date, should be YYYY-MM-DD")
 		}
 	}
 
-	if !fromDate.IsZero() && !toDate.IsZero() {
-		if fromDate.After(toDate) {
-			return fromDate, toDate, errors.New("from_date is greater than to_date")
+	if !filter.From.IsZero() && !filter.To.IsZero() {
+		if filter.From.After(filter.To) {
+			return filter, loc, errors.New("from_date is greater than to_date")
+		}
+	}
+
+	// to_date is included, so operations are selected till the start of the next day
+	if !filter.To.IsZero() {
+		filter.To = filter.To.AddDate(0, 0, 1)
+	}
+
+	if reportReq.Limit < 0 || reportReq.Limit > maxReportLimit {
+		return filter, loc, errors.Errorf("limit should be between 1 and %d", maxReportLimit)
+	}
+	if reportReq.Limit != 0 {
+		filter.Limit = reportReq.Limit
+	}
+
+	if reportReq.Cursor != "" {
+		cursor, err := types.ParseOperationsCursor(reportReq.Cursor)
+		if err != nil {
+			return filter, loc, err
 		}
+		filter.Cursor = &cursor
 	}
 
-	return fromDate, toDate, nil
+	return filter, loc, nil
 }
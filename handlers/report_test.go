@@ -2,6 +2,8 @@ package handlers_test
 
 import (
 	"bytes"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -153,19 +155,44 @@ func TestHandleReport(t *testing.T) {
 		assert.Equal(t, `{"error":"from_date is greater than to_date"}`, rr.Body.String())
 	})
 
-	t.Run("storage error", func(t *testing.T) {
-		body := bytes.NewReader([]byte(`{"from_date": "2030-01-01", "to_date": "2030-01-01","operation_type": "deposit"}`))
+	t.Run("validation error - unsupported locale", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"locale": "xx-XX"}`))
 		req, err := http.NewRequest(http.MethodPost, "/report", body)
 		require.NoError(t, err)
 
-		fromDate, _ := time.Parse("2006-02-01", "2030-01-01")
-		toDate := fromDate
+		params := []httprouter.Param{
+			{
+				Key:   "format",
+				Value: "csv",
+			},
+			{
+				Key:   "wallet",
+				Value: "walletID",
+			},
+		}
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleReport(rr, req, params)
 
-		storageMock := mocks.NewMockstorage(ctrl)
-		storageMock.EXPECT().
-			Operations(gomock.Any(), types.WalletID("walletID"), types.OperationTypeDeposit, fromDate, toDate).
-			Times(1).
-			Return(nil, errors.New("storage error"))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"unsupported locale"}`, rr.Body.String())
+	})
+
+	t.Run("validation error - unsupported timezone", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"timezone": "Mars/Olympus"}`))
+		req, err := http.NewRequest(http.MethodPost, "/report", body)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleReport(rr, req, reportParams("json"))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"unsupported timezone"}`, rr.Body.String())
+	})
+
+	t.Run("validation error - invalid limit", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"limit": 10001}`))
+		req, err := http.NewRequest(http.MethodPost, "/report", body)
+		require.NoError(t, err)
 
 		params := []httprouter.Param{
 			{
@@ -178,47 +205,140 @@ func TestHandleReport(t *testing.T) {
 			},
 		}
 		rr := httptest.NewRecorder()
-		handlers.New(nil, storageMock, nil).HandleReport(rr, req, params)
+		handlers.New(nil, nil, nil).HandleReport(rr, req, params)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"limit should be between 1 and 10000"}`, rr.Body.String())
+	})
+
+	t.Run("validation error - invalid cursor", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"cursor": "invalid"}`))
+		req, err := http.NewRequest(http.MethodPost, "/report", body)
+		require.NoError(t, err)
+
+		params := []httprouter.Param{
+			{
+				Key:   "format",
+				Value: "json",
+			},
+			{
+				Key:   "wallet",
+				Value: "walletID",
+			},
+		}
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleReport(rr, req, params)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"invalid cursor"}`, rr.Body.String())
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_date": "2030-01-01", "to_date": "2030-01-01","operation_type": "deposit"}`))
+		req, err := http.NewRequest(http.MethodPost, "/report", body)
+		require.NoError(t, err)
+
+		fromDate, _ := time.ParseInLocation(types.DateLayout, "2030-01-01", time.UTC)
+		toDate := fromDate.AddDate(0, 0, 1)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Operations(gomock.Any(), types.WalletID("walletID"), types.OperationsFilter{Type: types.OperationTypeDeposit, From: fromDate, To: toDate, Limit: 1000}).
+			Times(1).
+			Return(types.OperationsPage{}, errors.New("storage error"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleReport(rr, req, reportParams("json"))
 
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 		assert.Equal(t, `{"error":"fetch operations: storage error"}`, rr.Body.String())
 	})
 
+	t.Run("storage error - wallet not found", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_date": "2030-01-01", "to_date": "2030-01-01","operation_type": "deposit"}`))
+		req, err := http.NewRequest(http.MethodPost, "/report", body)
+		require.NoError(t, err)
+
+		fromDate, _ := time.ParseInLocation(types.DateLayout, "2030-01-01", time.UTC)
+		toDate := fromDate.AddDate(0, 0, 1)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Operations(gomock.Any(), types.WalletID("walletID"), types.OperationsFilter{Type: types.OperationTypeDeposit, From: fromDate, To: toDate, Limit: 1000}).
+			Times(1).
+			Return(types.OperationsPage{}, &types.WalletNotFoundError{Field: "wallet"})
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleReport(rr, req, reportParams("json"))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, `{"error":"wallet not found"}`, rr.Body.String())
+	})
+
 	t.Run("exporter error", func(t *testing.T) {
 		body := bytes.NewReader([]byte(`{"from_date": "2030-01-01", "to_date": "2030-01-01","operation_type": "deposit"}`))
 		req, err := http.NewRequest(http.MethodPost, "/report", body)
 		require.NoError(t, err)
 
-		fromDate, _ := time.Parse("2006-02-01", "2030-01-01")
-		toDate := fromDate
+		fromDate, _ := time.ParseInLocation(types.DateLayout, "2030-01-01", time.UTC)
+		toDate := fromDate.AddDate(0, 0, 1)
+		rows := &operationRows{}
 
 		storageMock := mocks.NewMockstorage(ctrl)
 		storageMock.EXPECT().
-			Operations(gomock.Any(), types.WalletID("walletID"), types.OperationTypeDeposit, fromDate, toDate).
+			Operations(gomock.Any(), types.WalletID("walletID"), types.OperationsFilter{Type: types.OperationTypeDeposit, From: fromDate, To: toDate, Limit: 1000}).
 			Times(1).
-			Return([]types.DBOperation{}, nil)
+			Return(types.OperationsPage{Rows: rows}, nil)
 
 		exporterMock := mocks.NewMockexporter(ctrl)
 		exporterMock.EXPECT().
-			Export(types.ExportFormatJSON, []types.ExportOperation{}).
+			Export(gomock.Any(), types.ExportFormatJSON, gomock.Any()).
 			Times(1).
-			Return(nil, errors.New("exporter error"))
+			DoAndReturn(func(w io.Writer, _ types.ExportFormat, _ types.ExportPage) error {
+				_, err := io.WriteString(w, `{"operations":[`)
+				require.NoError(t, err)
+				return errors.New("exporter error")
+			})
 
-		params := []httprouter.Param{
-			{
-				Key:   "format",
-				Value: "json",
-			},
-			{
-				Key:   "wallet",
-				Value: "walletID",
-			},
-		}
 		rr := httptest.NewRecorder()
-		handlers.New(nil, storageMock, exporterMock).HandleReport(rr, req, params)
+		handlers.New(nil, storageMock, exporterMock).HandleReport(rr, req, reportParams("json"))
 
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 		assert.Equal(t, `{"error":"export operations: exporter error"}`, rr.Body.String())
+		assert.True(t, rows.closed)
+	})
+
+	t.Run("exporter error - report is partially sent", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{}`))
+		req, err := http.NewRequest(http.MethodPost, "/report", body)
+		require.NoError(t, err)
+
+		rows := &operationRows{}
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Operations(gomock.Any(), types.WalletID("walletID"), types.OperationsFilter{Limit: 1000}).
+			Times(1).
+			Return(types.OperationsPage{Rows: rows}, nil)
+
+		exporterMock := mocks.NewMockexporter(ctrl)
+		exporterMock.EXPECT().
+			Export(gomock.Any(), types.ExportFormatCSV, gomock.Any()).
+			Times(1).
+			DoAndReturn(func(w io.Writer, _ types.ExportFormat, _ types.ExportPage) error {
+				_, err := w.Write(bytes.Repeat([]byte("a"), 64*1024))
+				require.NoError(t, err)
+				return errors.New("exporter error")
+			})
+
+		rr := httptest.NewRecorder()
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			handlers.New(nil, storageMock, exporterMock).HandleReport(rr, req, reportParams("csv"))
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.True(t, rr.Flushed)
+		assert.True(t, rows.closed)
 	})
 
 	t.Run("happy path", func(t *testing.T) {
@@ -226,35 +346,291 @@ func TestHandleReport(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, "/report", body)
 		require.NoError(t, err)
 
-		fromDate, _ := time.Parse("2006-02-01", "2030-01-01")
-		toDate := fromDate
+		fromDate, _ := time.ParseInLocation(types.DateLayout, "2030-01-01", time.UTC)
+		toDate := fromDate.AddDate(0, 0, 1)
+		rows := &operationRows{}
 
 		storageMock := mocks.NewMockstorage(ctrl)
 		storageMock.EXPECT().
-			Operations(gomock.Any(), types.WalletID("walletID"), types.OperationTypeDeposit, fromDate, toDate).
+			Operations(gomock.Any(), types.WalletID("walletID"), types.OperationsFilter{Type: types.OperationTypeDeposit, From: fromDate, To: toDate, Limit: 1000}).
 			Times(1).
-			Return([]types.DBOperation{}, nil)
+			Return(types.OperationsPage{Rows: rows}, nil)
 
 		exporterMock := mocks.NewMockexporter(ctrl)
 		exporterMock.EXPECT().
-			Export(types.ExportFormatJSON, []types.ExportOperation{}).
+			Export(gomock.Any(), types.ExportFormatJSON, gomock.Any()).
 			Times(1).
-			Return([]byte(`success`), nil)
+			DoAndReturn(func(w io.Writer, _ types.ExportFormat, page types.ExportPage) error {
+				assert.Empty(t, exportedOperations(page))
+				assert.Empty(t, page.NextCursor)
+				_, err := io.WriteString(w, `success`)
+				return err
+			})
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, exporterMock).HandleReport(rr, req, reportParams("json"))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		assert.Equal(t, `success`, rr.Body.String())
+		assert.True(t, rows.closed)
+	})
+
+	t.Run("happy path - empty csv", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{}`))
+		req, err := http.NewRequest(http.MethodPost, "/report", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Operations(gomock.Any(), types.WalletID("walletID"), types.OperationsFilter{Limit: 1000}).
+			Times(1).
+			Return(types.OperationsPage{Rows: &operationRows{}}, nil)
+
+		exporterMock := mocks.NewMockexporter(ctrl)
+		exporterMock.EXPECT().
+			Export(gomock.Any(), types.ExportFormatCSV, gomock.Any()).
+			Times(1).
+			Return(nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, exporterMock).HandleReport(rr, req, reportParams("csv"))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, rr.Body.String())
+	})
+
+	t.Run("happy path - locale", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"locale": "de-DE"}`))
+		req, err := http.NewRequest(http.MethodPost, "/report", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Operations(gomock.Any(), types.WalletID("walletID"), types.OperationsFilter{Limit: 1000}).
+			Times(1).
+			Return(types.OperationsPage{
+				Rows: &operationRows{ops: []types.DBOperation{
+					{
+						ID:            2,
+						PublicID:      "0b1e6f2d-3c4a-4d5b-8e6f-7a8b9c0d1e2f",
+						WalletID:      "walletID",
+						OperationType: types.OperationTypeReversal,
+						Amount:        -123450,
+						Currency:      "EUR",
+						ReversalOf:    "7f3c1a52-9d4e-4b8a-a1f6-2c5e8d9b0a13",
+						Balance:       100000,
+						CreatedAt:     time.Date(2030, 1, 1, 10, 30, 0, 123456000, time.UTC),
+					},
+				}},
+				Currency:       "EUR",
+				OpeningBalance: 223450,
+				ClosingBalance: 100000,
+			}, nil)
+
+		exporterMock := mocks.NewMockexporter(ctrl)
+		exporterMock.EXPECT().
+			Export(gomock.Any(), types.ExportFormatCSV, gomock.Any()).
+			Times(1).
+			DoAndReturn(func(w io.Writer, _ types.ExportFormat, page types.ExportPage) error {
+				assert.Equal(t, []types.ExportOperation{
+					{
+						ID:            "0b1e6f2d-3c4a-4d5b-8e6f-7a8b9c0d1e2f",
+						WalletID:      "walletID",
+						OperationType: "reversal",
+						Amount:        "-1.234,50 €",
+						Currency:      "EUR",
+						ReversalOf:    "7f3c1a52-9d4e-4b8a-a1f6-2c5e8d9b0a13",
+						Balance:       "1.000,00 €",
+						Date:          "2030-01-01T10:30:00.123456Z",
+					},
+				}, exportedOperations(page))
+				assert.True(t, page.FirstPage)
+				assert.Equal(t, types.ExportOperation{
+					WalletID:      "walletID",
+					OperationType: "opening_balance",
+					Currency:      "EUR",
+					Balance:       "2.234,50 €",
+				}, page.OpeningBalance)
+				assert.Equal(t, types.ExportOperation{
+					WalletID:      "walletID",
+					OperationType: "closing_balance",
+					Currency:      "EUR",
+					Balance:       "1.000,00 €",
+				}, page.ClosingBalance)
+				_, err := io.WriteString(w, `success`)
+				return err
+			})
 
-		params := []httprouter.Param{
-			{
-				Key:   "format",
-				Value: "json",
-			},
-			{
-				Key:   "wallet",
-				Value: "walletID",
-			},
-		}
 		rr := httptest.NewRecorder()
-		handlers.New(nil, storageMock, exporterMock).HandleReport(rr, req, params)
+		handlers.New(nil, storageMock, exporterMock).HandleReport(rr, req, reportParams("csv"))
 
 		assert.Equal(t, http.StatusOK, rr.Code)
 		assert.Equal(t, `success`, rr.Body.String())
 	})
+
+	t.Run("happy path - timezone", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_date": "2030-01-01", "to_date": "2030-01-31", "timezone": "Asia/Tokyo"}`))
+		req, err := http.NewRequest(http.MethodPost, "/report", body)
+		require.NoError(t, err)
+
+		tokyo, err := time.LoadLocation("Asia/Tokyo")
+		require.NoError(t, err)
+		fromDate := time.Date(2030, 1, 1, 0, 0, 0, 0, tokyo)
+		toDate := time.Date(2030, 2, 1, 0, 0, 0, 0, tokyo)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Operations(gomock.Any(), types.WalletID("walletID"), types.OperationsFilter{From: fromDate, To: toDate, Limit: 1000}).
+			Times(1).
+			Return(types.OperationsPage{
+				Rows: &operationRows{ops: []types.DBOperation{
+					{
+						ID:            1,
+						PublicID:      "7f3c1a52-9d4e-4b8a-a1f6-2c5e8d9b0a13",
+						WalletID:      "walletID",
+						OperationType: types.OperationTypeDeposit,
+						Amount:        100,
+						Currency:      "USD",
+						Balance:       100,
+						CreatedAt:     time.Date(2030, 1, 1, 20, 0, 0, 0, time.UTC),
+					},
+				}},
+				Currency:       "USD",
+				ClosingBalance: 100,
+			}, nil)
+
+		exporterMock := mocks.NewMockexporter(ctrl)
+		exporterMock.EXPECT().
+			Export(gomock.Any(), types.ExportFormatJSON, gomock.Any()).
+			Times(1).
+			DoAndReturn(func(w io.Writer, _ types.ExportFormat, page types.ExportPage) error {
+				ops := exportedOperations(page)
+				require.Len(t, ops, 1)
+				assert.Equal(t, "2030-01-02T05:00:00+09:00", ops[0].Date)
+				assert.Equal(t, "2030-01-01T00:00:00+09:00", page.OpeningBalance.Date)
+				assert.Equal(t, "2030-02-01T00:00:00+09:00", page.ClosingBalance.Date)
+				_, err := io.WriteString(w, `success`)
+				return err
+			})
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, exporterMock).HandleReport(rr, req, reportParams("json"))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `success`, rr.Body.String())
+	})
+
+	t.Run("happy path - next page json", func(t *testing.T) {
+		cursor := types.OperationsCursor{CreatedAt: time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC), ID: 5}
+		next := types.OperationsCursor{CreatedAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), ID: 3}
+
+		body := bytes.NewReader([]byte(fmt.Sprintf(`{"limit": 2, "cursor": "%s"}`, cursor)))
+		req, err := http.NewRequest(http.MethodPost, "/report/json/walletID", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Operations(gomock.Any(), types.WalletID("walletID"), types.OperationsFilter{Cursor: &cursor, Limit: 2}).
+			Times(1).
+			Return(types.OperationsPage{Rows: &operationRows{}, NextCursor: &next}, nil)
+
+		exporterMock := mocks.NewMockexporter(ctrl)
+		exporterMock.EXPECT().
+			Export(gomock.Any(), types.ExportFormatJSON, gomock.Any()).
+			Times(1).
+			DoAndReturn(func(w io.Writer, _ types.ExportFormat, page types.ExportPage) error {
+				assert.Equal(t, next.String(), page.NextCursor)
+				_, err := io.WriteString(w, `success`)
+				return err
+			})
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, exporterMock).HandleReport(rr, req, reportParams("json"))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, rr.Header().Get("Link"))
+		assert.Equal(t, `success`, rr.Body.String())
+	})
+
+	t.Run("happy path - next page csv", func(t *testing.T) {
+		cursor := types.OperationsCursor{CreatedAt: time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC), ID: 5}
+		next := types.OperationsCursor{CreatedAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), ID: 3}
+
+		body := bytes.NewReader([]byte(`{"limit": 2}`))
+		req, err := http.NewRequest(http.MethodPost, "/report/csv/walletID?cursor="+cursor.String(), body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Operations(gomock.Any(), types.WalletID("walletID"), types.OperationsFilter{Cursor: &cursor, Limit: 2}).
+			Times(1).
+			Return(types.OperationsPage{Rows: &operationRows{}, NextCursor: &next}, nil)
+
+		exporterMock := mocks.NewMockexporter(ctrl)
+		exporterMock.EXPECT().
+			Export(gomock.Any(), types.ExportFormatCSV, gomock.Any()).
+			Times(1).
+			DoAndReturn(func(w io.Writer, _ types.ExportFormat, page types.ExportPage) error {
+				assert.Equal(t, next.String(), page.NextCursor)
+				_, err := io.WriteString(w, `success`)
+				return err
+			})
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, exporterMock).HandleReport(rr, req, reportParams("csv"))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, fmt.Sprintf(`</report/csv/walletID?cursor=%s>; rel="next"`, next), rr.Header().Get("Link"))
+		assert.Equal(t, `success`, rr.Body.String())
+	})
+}
+
+func reportParams(format string) httprouter.Params {
+	return httprouter.Params{
+		{
+			Key:   "format",
+			Value: format,
+		},
+		{
+			Key:   "wallet",
+			Value: "walletID",
+		},
+	}
+}
+
+func exportedOperations(page types.ExportPage) []types.ExportOperation {
+	var ops []types.ExportOperation
+	for page.Operations.Next() {
+		ops = append(ops, page.Operations.Operation())
+	}
+	return ops
+}
+
+// operationRows iterates over ops and remembers whether it's closed
+type operationRows struct {
+	ops    []types.DBOperation
+	cur    int
+	closed bool
+}
+
+func (r *operationRows) Next() bool {
+	if r.cur >= len(r.ops) {
+		return false
+	}
+	r.cur++
+	return true
+}
+
+func (r *operationRows) Operation() types.DBOperation {
+	return r.ops[r.cur-1]
+}
+
+func (r *operationRows) Err() error {
+	return nil
+}
+
+func (r *operationRows) Close() error {
+	r.closed = true
+	return nil
 }
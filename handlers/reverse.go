@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type reverseRequest struct {
+	Amount types.Money `json:"amount"`
+}
+
+type reverseResponse struct {
+	OperationIDs []types.OperationID `json:"operation_ids"`
+}
+
+func (h *Handler) HandleReverse(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	operationID := types.OperationID(params.ByName("id"))
+	if !operationID.Valid() {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("invalid operation id"))
+		return
+	}
+
+	// empty body means full reversal
+	var reverseReq reverseRequest
+	if err := json.NewDecoder(r.Body).Decode(&reverseReq); err != nil && err != io.EOF {
+		writeErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "decode request"))
+		return
+	}
+
+	if reverseReq.Amount < 0 {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("invalid amount"))
+		return
+	}
+
+	idemKey, err := idempotencyKey(r, "reverse:"+string(operationID), reverseReq)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	operationIDs, err := h.s.Reverse(r.Context(), operationID, reverseReq.Amount, idemKey)
+	if err != nil {
+		switch cause := errors.Cause(err); cause {
+		case types.ErrOperationNotFound:
+			writeErrorResponse(w, http.StatusNotFound, cause)
+			return
+		case types.ErrWalletFrozen:
+			writeErrorResponse(w, http.StatusForbidden, walletStatusError(err))
+			return
+		case types.ErrWalletClosed:
+			writeErrorResponse(w, http.StatusConflict, walletStatusError(err))
+			return
+		case types.ErrInvalidReversalAmount, types.ErrUnavailableBalance, types.ErrConvertedAmountTooSmall, types.ErrAmountOverflow:
+			writeErrorResponse(w, http.StatusBadRequest, cause)
+			return
+		case types.ErrOperationNotReversible, types.ErrOperationAlreadyReversed, types.ErrIdempotencyKeyReused:
+			writeErrorResponse(w, http.StatusConflict, cause)
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "save to storage"))
+		return
+	}
+
+	resp, err := json.Marshal(&reverseResponse{OperationIDs: operationIDs})
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "marshal response"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		log.WithError(err).Error("failed to write successful response")
+	}
+}
@@ -0,0 +1,151 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleReverse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := []httprouter.Param{
+		{
+			Key:   "id",
+			Value: "7f3c1a52-9d4e-4b8a-a1f6-2c5e8d9b0a13",
+		},
+	}
+
+	t.Run("validation error - invalid operation id", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/operations/10/reverse", bytes.NewReader([]byte(`{}`)))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleReverse(rr, req, []httprouter.Param{{Key: "id", Value: "10"}})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"invalid operation id"}`, rr.Body.String())
+	})
+
+	t.Run("validation error - invalid amount", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/operations/7f3c1a52-9d4e-4b8a-a1f6-2c5e8d9b0a13/reverse", bytes.NewReader([]byte(`{"amount": -1}`)))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleReverse(rr, req, params)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"invalid amount"}`, rr.Body.String())
+	})
+
+	storageErrors := []struct {
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			err:          types.ErrOperationNotFound,
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"error":"operation not found"}`,
+		},
+		{
+			err:          types.ErrInvalidReversalAmount,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"reversal amount exceeds not reversed amount of operation"}`,
+		},
+		{
+			err:          types.ErrUnavailableBalance,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"insufficient funds in the account"}`,
+		},
+		{
+			err:          types.ErrOperationNotReversible,
+			expectedCode: http.StatusConflict,
+			expectedBody: `{"error":"operation can't be reversed"}`,
+		},
+		{
+			err:          types.ErrOperationAlreadyReversed,
+			expectedCode: http.StatusConflict,
+			expectedBody: `{"error":"operation is already fully reversed"}`,
+		},
+		{
+			err:          types.ErrIdempotencyKeyReused,
+			expectedCode: http.StatusConflict,
+			expectedBody: `{"error":"idempotency key is already used for another request"}`,
+		},
+		{
+			err:          errors.New("storage error"),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"error":"save to storage: rolled back: storage error"}`,
+		},
+	}
+
+	for _, c := range storageErrors {
+		t.Run("storage error - "+c.err.Error(), func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/operations/7f3c1a52-9d4e-4b8a-a1f6-2c5e8d9b0a13/reverse", bytes.NewReader([]byte(`{"amount": 50}`)))
+			require.NoError(t, err)
+
+			storageMock := mocks.NewMockstorage(ctrl)
+			storageMock.EXPECT().
+				Reverse(gomock.Any(), types.OperationID("7f3c1a52-9d4e-4b8a-a1f6-2c5e8d9b0a13"), types.Money(50), nil).
+				Times(1).
+				Return(nil, errors.Wrap(c.err, "rolled back"))
+
+			rr := httptest.NewRecorder()
+			handlers.New(nil, storageMock, nil).HandleReverse(rr, req, params)
+
+			assert.Equal(t, c.expectedCode, rr.Code)
+			assert.Equal(t, c.expectedBody, rr.Body.String())
+		})
+	}
+
+	t.Run("happy path - full reversal with empty body", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/operations/7f3c1a52-9d4e-4b8a-a1f6-2c5e8d9b0a13/reverse", bytes.NewReader(nil))
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Reverse(gomock.Any(), types.OperationID("7f3c1a52-9d4e-4b8a-a1f6-2c5e8d9b0a13"), types.Money(0), nil).
+			Times(1).
+			Return([]types.OperationID{"0b1e6f2d-3c4a-4d5b-8e6f-7a8b9c0d1e2f", "5d6e7f80-9a1b-4c2d-9e3f-4a5b6c7d8e9f"}, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleReverse(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"operation_ids":["0b1e6f2d-3c4a-4d5b-8e6f-7a8b9c0d1e2f","5d6e7f80-9a1b-4c2d-9e3f-4a5b6c7d8e9f"]}`, rr.Body.String())
+	})
+
+	t.Run("happy path - partial reversal with idempotency key", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/operations/7f3c1a52-9d4e-4b8a-a1f6-2c5e8d9b0a13/reverse", bytes.NewReader([]byte(`{"amount": 50}`)))
+		require.NoError(t, err)
+		req.Header.Set("Idempotency-Key", "key")
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Reverse(gomock.Any(), types.OperationID("7f3c1a52-9d4e-4b8a-a1f6-2c5e8d9b0a13"), types.Money(50), gomock.Not(gomock.Nil())).
+			Times(1).
+			DoAndReturn(func(_ context.Context, _ types.OperationID, _ types.Money, idemKey *types.IdempotencyKey) ([]types.OperationID, error) {
+				assert.Equal(t, "key", idemKey.Key)
+				return []types.OperationID{"0b1e6f2d-3c4a-4d5b-8e6f-7a8b9c0d1e2f"}, nil
+			})
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleReverse(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"operation_ids":["0b1e6f2d-3c4a-4d5b-8e6f-7a8b9c0d1e2f"]}`, rr.Body.String())
+	})
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/fx"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type saveFXRatesRequest struct {
+	Rates []types.FXRate `json:"rates"`
+}
+
+type saveFXRatesResponse struct {
+	Saved int64 `json:"saved"`
+}
+
+func (h *Handler) HandleSaveFXRates(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var saveReq saveFXRatesRequest
+	if err := json.NewDecoder(r.Body).Decode(&saveReq); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "decode request"))
+		return
+	}
+
+	if len(saveReq.Rates) == 0 {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("rates are required"))
+		return
+	}
+
+	for i, rate := range saveReq.Rates {
+		if err := fx.Validate(rate); err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, errors.Wrapf(err, "rate %d", i))
+			return
+		}
+	}
+
+	saved, err := h.s.SaveRates(r.Context(), saveReq.Rates)
+	if err != nil {
+		if errors.Cause(err) == types.ErrFXRateConflict {
+			writeErrorResponse(w, http.StatusConflict, err)
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "save to storage"))
+		return
+	}
+
+	resp, err := json.Marshal(&saveFXRatesResponse{Saved: saved})
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "marshal response"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		log.WithError(err).Error("failed to write successful response")
+	}
+}
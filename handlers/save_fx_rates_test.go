@@ -0,0 +1,118 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSaveFXRates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("validation error - empty rates", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/admin/fx/rates", bytes.NewReader([]byte(`{"rates": []}`)))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleSaveFXRates(rr, req, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"rates are required"}`, rr.Body.String())
+	})
+
+	t.Run("validation error - invalid rate", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"rates": [{"base_currency": "USD", "quote_currency": "EUR", "rate": "0", "effective_from": "2030-01-01T00:00:00Z"}]}`))
+		req, err := http.NewRequest(http.MethodPost, "/admin/fx/rates", body)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleSaveFXRates(rr, req, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"rate 0: rate must be a positive decimal number"}`, rr.Body.String())
+	})
+
+	t.Run("validation error - unsupported currency", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"rates": [{"base_currency": "USD", "quote_currency": "XXX", "rate": "1.5", "effective_from": "2030-01-01T00:00:00Z"}]}`))
+		req, err := http.NewRequest(http.MethodPost, "/admin/fx/rates", body)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleSaveFXRates(rr, req, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"rate 0: unsupported quote currency \"XXX\""}`, rr.Body.String())
+	})
+
+	rates := []types.FXRate{
+		{
+			BaseCurrency:  "USD",
+			QuoteCurrency: "EUR",
+			Rate:          "0.9154",
+			EffectiveFrom: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	payload := []byte(`{"rates": [{"base_currency": "USD", "quote_currency": "EUR", "rate": "0.9154", "effective_from": "2030-01-01T00:00:00Z"}]}`)
+
+	t.Run("storage error", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/admin/fx/rates", bytes.NewReader(payload))
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			SaveRates(gomock.Any(), rates).
+			Times(1).
+			Return(int64(0), errors.New("storage error"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleSaveFXRates(rr, req, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, `{"error":"save to storage: storage error"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - rate conflict", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/admin/fx/rates", bytes.NewReader(payload))
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			SaveRates(gomock.Any(), rates).
+			Times(1).
+			Return(int64(0), errors.Wrap(types.ErrFXRateConflict, "USD/EUR from 2030-01-01T00:00:00Z"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleSaveFXRates(rr, req, nil)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Equal(t, `{"error":"USD/EUR from 2030-01-01T00:00:00Z: another rate is already saved for the currency pair and effective time"}`, rr.Body.String())
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/admin/fx/rates", bytes.NewReader(payload))
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			SaveRates(gomock.Any(), rates).
+			Times(1).
+			Return(int64(1), nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleSaveFXRates(rr, req, nil)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"saved":1}`, rr.Body.String())
+	})
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type scheduleRunsResponse struct {
+	ScheduleID types.ScheduleID    `json:"schedule_id"`
+	Runs       []types.ScheduleRun `json:"runs"`
+}
+
+func (h *Handler) HandleScheduleRuns(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	scheduleID := types.ScheduleID(params.ByName("id"))
+	if !scheduleID.Valid() {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("invalid schedule id"))
+		return
+	}
+
+	runs, err := h.s.ScheduleRuns(r.Context(), scheduleID)
+	if err != nil {
+		if errors.Cause(err) == types.ErrScheduleNotFound {
+			writeErrorResponse(w, http.StatusNotFound, types.ErrScheduleNotFound)
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "fetch schedule runs"))
+		return
+	}
+
+	if runs == nil {
+		runs = []types.ScheduleRun{}
+	}
+
+	resp, err := json.Marshal(&scheduleRunsResponse{ScheduleID: scheduleID, Runs: runs})
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "marshal response"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		log.WithError(err).Error("failed to write successful response")
+	}
+}
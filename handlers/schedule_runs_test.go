@@ -0,0 +1,107 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleScheduleRuns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := []httprouter.Param{
+		{
+			Key:   "id",
+			Value: "0c5e8a9d-3f21-4b7a-9e6d-2a8f1c4b7e90",
+		},
+	}
+
+	t.Run("validation error - invalid schedule id", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/schedules/scheduleID/runs", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleScheduleRuns(rr, req, []httprouter.Param{{Key: "id", Value: "scheduleID"}})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"invalid schedule id"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - schedule not found", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/schedules/0c5e8a9d-3f21-4b7a-9e6d-2a8f1c4b7e90/runs", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			ScheduleRuns(gomock.Any(), types.ScheduleID("0c5e8a9d-3f21-4b7a-9e6d-2a8f1c4b7e90")).
+			Times(1).
+			Return(nil, types.ErrScheduleNotFound)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleScheduleRuns(rr, req, params)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, `{"error":"schedule not found"}`, rr.Body.String())
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/schedules/0c5e8a9d-3f21-4b7a-9e6d-2a8f1c4b7e90/runs", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			ScheduleRuns(gomock.Any(), types.ScheduleID("0c5e8a9d-3f21-4b7a-9e6d-2a8f1c4b7e90")).
+			Times(1).
+			Return(nil, errors.New("storage error"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleScheduleRuns(rr, req, params)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, `{"error":"fetch schedule runs: storage error"}`, rr.Body.String())
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/schedules/0c5e8a9d-3f21-4b7a-9e6d-2a8f1c4b7e90/runs", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			ScheduleRuns(gomock.Any(), types.ScheduleID("0c5e8a9d-3f21-4b7a-9e6d-2a8f1c4b7e90")).
+			Times(1).
+			Return([]types.ScheduleRun{
+				{
+					ScheduleID: "0c5e8a9d-3f21-4b7a-9e6d-2a8f1c4b7e90",
+					RunAt:      time.Date(2022, time.January, 3, 10, 0, 0, 0, time.UTC),
+					TransferID: "transferID",
+					ExecutedAt: time.Date(2022, time.January, 3, 10, 0, 5, 0, time.UTC),
+				},
+				{
+					ScheduleID: "0c5e8a9d-3f21-4b7a-9e6d-2a8f1c4b7e90",
+					RunAt:      time.Date(2022, time.January, 10, 10, 0, 0, 0, time.UTC),
+					Error:      "insufficient funds in the account",
+					ExecutedAt: time.Date(2022, time.January, 10, 10, 0, 5, 0, time.UTC),
+				},
+			}, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleScheduleRuns(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"schedule_id":"0c5e8a9d-3f21-4b7a-9e6d-2a8f1c4b7e90","runs":[`+
+			`{"run_at":"2022-01-03T10:00:00Z","transfer_id":"transferID","executed_at":"2022-01-03T10:00:05Z"},`+
+			`{"run_at":"2022-01-10T10:00:00Z","error":"insufficient funds in the account","executed_at":"2022-01-10T10:00:05Z"}]}`,
+			rr.Body.String())
+	})
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+type setOverdraftLimitRequest struct {
+	OverdraftLimit *types.Money `json:"overdraft_limit"`
+}
+
+func (h *Handler) HandleSetOverdraftLimit(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	walletID := params.ByName("wallet")
+	if walletID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("empty wallet id"))
+		return
+	}
+
+	var limitReq setOverdraftLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&limitReq); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "decode request"))
+		return
+	}
+
+	// zero limit is valid, it disables overdraft
+	if limitReq.OverdraftLimit == nil {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("overdraft_limit is required"))
+		return
+	}
+	if *limitReq.OverdraftLimit < 0 {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("invalid overdraft_limit"))
+		return
+	}
+
+	if err := h.s.SetOverdraftLimit(r.Context(), types.WalletID(walletID), *limitReq.OverdraftLimit); err != nil {
+		switch cause := errors.Cause(err); cause {
+		case types.ErrWalletNotFound:
+			writeErrorResponse(w, http.StatusNotFound, walletNotFoundError(err))
+			return
+		case types.ErrWalletClosed, types.ErrOverdraftLimitTooLow:
+			writeErrorResponse(w, http.StatusConflict, cause)
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "save to storage"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,118 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSetOverdraftLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := []httprouter.Param{
+		{
+			Key:   "wallet",
+			Value: "walletID",
+		},
+	}
+
+	t.Run("validation error - missing limit", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/admin/wallet/walletID/overdraft", bytes.NewReader([]byte(`{}`)))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleSetOverdraftLimit(rr, req, params)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"overdraft_limit is required"}`, rr.Body.String())
+	})
+
+	t.Run("validation error - negative limit", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/admin/wallet/walletID/overdraft", bytes.NewReader([]byte(`{"overdraft_limit": -1}`)))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleSetOverdraftLimit(rr, req, params)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"invalid overdraft_limit"}`, rr.Body.String())
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/admin/wallet/walletID/overdraft", bytes.NewReader([]byte(`{"overdraft_limit": 10000}`)))
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			SetOverdraftLimit(gomock.Any(), types.WalletID("walletID"), types.Money(10000)).
+			Times(1).
+			Return(errors.New("storage error"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleSetOverdraftLimit(rr, req, params)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, `{"error":"save to storage: storage error"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - limit is less than used credit", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/admin/wallet/walletID/overdraft", bytes.NewReader([]byte(`{"overdraft_limit": 0}`)))
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			SetOverdraftLimit(gomock.Any(), types.WalletID("walletID"), types.Money(0)).
+			Times(1).
+			Return(errors.Wrap(types.ErrOverdraftLimitTooLow, "rolled back"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleSetOverdraftLimit(rr, req, params)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Equal(t, `{"error":"overdraft limit is less than used credit"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - wallet not found", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/admin/wallet/walletID/overdraft", bytes.NewReader([]byte(`{"overdraft_limit": 10000}`)))
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			SetOverdraftLimit(gomock.Any(), types.WalletID("walletID"), types.Money(10000)).
+			Times(1).
+			Return(&types.WalletNotFoundError{Field: "wallet"})
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleSetOverdraftLimit(rr, req, params)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, `{"error":"wallet not found"}`, rr.Body.String())
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/admin/wallet/walletID/overdraft", bytes.NewReader([]byte(`{"overdraft_limit": "10000"}`)))
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			SetOverdraftLimit(gomock.Any(), types.WalletID("walletID"), types.Money(10000)).
+			Times(1).
+			Return(nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleSetOverdraftLimit(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+type setWalletStatusRequest struct {
+	Status types.WalletStatus `json:"status"`
+	Reason string             `json:"reason"`
+}
+
+func (h *Handler) HandleSetWalletStatus(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	walletID := params.ByName("wallet")
+	if walletID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("empty wallet id"))
+		return
+	}
+
+	var statusReq setWalletStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&statusReq); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "decode request"))
+		return
+	}
+
+	if _, ok := types.AllWalletStatuses[statusReq.Status]; !ok {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("unexpected wallet status"))
+		return
+	}
+
+	// reason is kept in the status history for audit
+	if strings.TrimSpace(statusReq.Reason) == "" {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("reason is required"))
+		return
+	}
+
+	if err := h.s.SetWalletStatus(r.Context(), types.WalletID(walletID), statusReq.Status, statusReq.Reason); err != nil {
+		switch cause := errors.Cause(err); cause {
+		case types.ErrWalletNotFound:
+			writeErrorResponse(w, http.StatusNotFound, walletNotFoundError(err))
+			return
+		case types.ErrWalletClosed, types.ErrWalletStatusUnchanged, types.ErrWalletNotEmpty:
+			writeErrorResponse(w, http.StatusConflict, cause)
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "save to storage"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+}
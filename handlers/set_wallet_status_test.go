@@ -0,0 +1,137 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSetWalletStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := []httprouter.Param{
+		{
+			Key:   "wallet",
+			Value: "walletID",
+		},
+	}
+
+	t.Run("validation error - empty wallet", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/admin/wallet//status", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleSetWalletStatus(rr, req, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"empty wallet id"}`, rr.Body.String())
+	})
+
+	t.Run("validation error - unexpected status", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"status": "blocked", "reason": "investigation"}`))
+		req, err := http.NewRequest(http.MethodPost, "/admin/wallet/walletID/status", body)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleSetWalletStatus(rr, req, params)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"unexpected wallet status"}`, rr.Body.String())
+	})
+
+	t.Run("validation error - empty reason", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"status": "frozen", "reason": " "}`))
+		req, err := http.NewRequest(http.MethodPost, "/admin/wallet/walletID/status", body)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleSetWalletStatus(rr, req, params)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"reason is required"}`, rr.Body.String())
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"status": "frozen", "reason": "investigation"}`))
+		req, err := http.NewRequest(http.MethodPost, "/admin/wallet/walletID/status", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			SetWalletStatus(gomock.Any(), types.WalletID("walletID"), types.WalletStatusFrozen, "investigation").
+			Times(1).
+			Return(errors.New("storage error"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleSetWalletStatus(rr, req, params)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, `{"error":"save to storage: storage error"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - wallet not found", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"status": "frozen", "reason": "investigation"}`))
+		req, err := http.NewRequest(http.MethodPost, "/admin/wallet/walletID/status", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			SetWalletStatus(gomock.Any(), types.WalletID("walletID"), types.WalletStatusFrozen, "investigation").
+			Times(1).
+			Return(errors.Wrap(&types.WalletNotFoundError{Field: "wallet"}, "rolled back"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleSetWalletStatus(rr, req, params)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, `{"error":"wallet not found"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - conflicts", func(t *testing.T) {
+		for _, storageErr := range []error{types.ErrWalletClosed, types.ErrWalletStatusUnchanged, types.ErrWalletNotEmpty} {
+			body := bytes.NewReader([]byte(`{"status": "closed", "reason": "customer request"}`))
+			req, err := http.NewRequest(http.MethodPost, "/admin/wallet/walletID/status", body)
+			require.NoError(t, err)
+
+			storageMock := mocks.NewMockstorage(ctrl)
+			storageMock.EXPECT().
+				SetWalletStatus(gomock.Any(), types.WalletID("walletID"), types.WalletStatusClosed, "customer request").
+				Times(1).
+				Return(errors.Wrap(storageErr, "rolled back"))
+
+			rr := httptest.NewRecorder()
+			handlers.New(nil, storageMock, nil).HandleSetWalletStatus(rr, req, params)
+
+			assert.Equal(t, http.StatusConflict, rr.Code)
+			assert.Equal(t, `{"error":"`+storageErr.Error()+`"}`, rr.Body.String())
+		}
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"status": "active", "reason": "investigation completed"}`))
+		req, err := http.NewRequest(http.MethodPost, "/admin/wallet/walletID/status", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			SetWalletStatus(gomock.Any(), types.WalletID("walletID"), types.WalletStatusActive, "investigation completed").
+			Times(1).
+			Return(nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleSetWalletStatus(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
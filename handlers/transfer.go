@@ -7,12 +7,18 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"github.com/justteddy/wallet/types"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 type transferRequest struct {
-	FromWallet types.WalletID `json:"from_wallet"`
-	ToWallet   types.WalletID `json:"to_wallet"`
-	Amount     int            `json:"amount"`
+	FromWallet    types.WalletID `json:"from_wallet"`
+	ToWallet      types.WalletID `json:"to_wallet"`
+	Amount        types.Money    `json:"amount"`
+	DecimalAmount string         `json:"decimal_amount,omitempty"`
+}
+
+type transferResponse struct {
+	TransferID types.TransferID `json:"transfer_id"`
 }
 
 func (h *Handler) HandleTransfer(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
@@ -27,17 +33,63 @@ func (h *Handler) HandleTransfer(w http.ResponseWriter, r *http.Request, params
 		return
 	}
 
-	if err := h.s.Transfer(r.Context(), transferReq.FromWallet, transferReq.ToWallet, transferReq.Amount); err != nil {
-		if errors.Cause(err) == types.ErrUnavailableBalance {
+	if transferReq.DecimalAmount != "" {
+		amount, ok := h.decimalAmount(w, r, transferReq.FromWallet, "from_wallet", transferReq.DecimalAmount)
+		if !ok {
+			return
+		}
+		transferReq.Amount, transferReq.DecimalAmount = amount, ""
+	}
+
+	idemKey, err := idempotencyKey(r, "transfer", transferReq)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	transferID, err := h.s.Transfer(r.Context(), transferReq.FromWallet, transferReq.ToWallet, transferReq.Amount, idemKey)
+	if err != nil {
+		switch errors.Cause(err) {
+		case types.ErrWalletNotFound:
+			writeErrorResponse(w, http.StatusNotFound, walletNotFoundError(err))
+			return
+		case types.ErrWalletFrozen:
+			writeErrorResponse(w, http.StatusForbidden, walletStatusError(err))
+			return
+		case types.ErrWalletClosed:
+			writeErrorResponse(w, http.StatusConflict, walletStatusError(err))
+			return
+		case types.ErrUnavailableBalance:
 			writeErrorResponse(w, http.StatusBadRequest, types.ErrUnavailableBalance)
 			return
+		case types.ErrFXRateNotFound, types.ErrConvertedAmountTooSmall:
+			writeErrorResponse(w, http.StatusBadRequest, errors.Cause(err))
+			return
+		case types.ErrAmountOverflow:
+			writeErrorResponse(w, http.StatusBadRequest, types.ErrAmountOverflow)
+			return
+		case types.ErrLimitExceeded:
+			writeLimitExceededResponse(w, err)
+			return
+		case types.ErrIdempotencyKeyReused:
+			writeErrorResponse(w, http.StatusConflict, types.ErrIdempotencyKeyReused)
+			return
 		}
 		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "save to storage"))
 		return
 	}
 
+	resp, err := json.Marshal(&transferResponse{TransferID: transferID})
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "marshal response"))
+		return
+	}
+
 	w.Header().Add("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		log.WithError(err).Error("failed to write successful response")
+	}
 }
 
 func (h *Handler) validateTransferRequest(transferReq transferRequest) error {
@@ -53,6 +105,14 @@ func (h *Handler) validateTransferRequest(transferReq transferRequest) error {
 		return errors.New("similar wallets provided")
 	}
 
+	// decimal amount is validated in currency of from_wallet
+	if transferReq.DecimalAmount != "" {
+		if transferReq.Amount != 0 {
+			return errAmountsMutuallyExclusive
+		}
+		return nil
+	}
+
 	if transferReq.Amount <= 0 {
 		return errors.New("invalid amount")
 	}
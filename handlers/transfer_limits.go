@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/currency"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type transferLimitsResponse struct {
+	Limits []types.TransferLimits `json:"limits"`
+}
+
+func (h *Handler) HandleSetTransferLimits(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var limits types.TransferLimits
+	if err := json.NewDecoder(r.Body).Decode(&limits); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, errors.Wrap(err, "decode request"))
+		return
+	}
+
+	if err := validateTransferLimits(limits); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.s.SetTransferLimits(r.Context(), limits); err != nil {
+		if errors.Cause(err) == types.ErrWalletNotFound {
+			writeErrorResponse(w, http.StatusNotFound, walletNotFoundError(err))
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "save to storage"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) HandleTransferLimits(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	limits, err := h.s.TransferLimits(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "fetch transfer limits"))
+		return
+	}
+
+	if limits == nil {
+		limits = []types.TransferLimits{}
+	}
+
+	resp, err := json.Marshal(&transferLimitsResponse{Limits: limits})
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "marshal response"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		log.WithError(err).Error("failed to write successful response")
+	}
+}
+
+func validateTransferLimits(limits types.TransferLimits) error {
+	if (limits.WalletID == "") == (limits.Currency == "") {
+		return errors.New("exactly one of wallet_id and currency is required")
+	}
+
+	if limits.Currency != "" {
+		if _, ok := currency.Lookup(limits.Currency); !ok {
+			return errors.New("unsupported currency")
+		}
+	}
+
+	// nil limit is valid, it disables the limit
+	if limits.MaxAmount != nil && *limits.MaxAmount <= 0 {
+		return errors.New("invalid max_amount")
+	}
+	if limits.MaxDailyAmount != nil && *limits.MaxDailyAmount <= 0 {
+		return errors.New("invalid max_daily_amount")
+	}
+	if limits.MaxHourlyCount != nil && *limits.MaxHourlyCount <= 0 {
+		return errors.New("invalid max_hourly_count")
+	}
+
+	return nil
+}
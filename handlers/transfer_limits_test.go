@@ -0,0 +1,188 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSetTransferLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	validationErrors := []struct {
+		name         string
+		body         string
+		expectedBody string
+	}{
+		{
+			name:         "missing wallet and currency",
+			body:         `{"max_amount": 100}`,
+			expectedBody: `{"error":"exactly one of wallet_id and currency is required"}`,
+		},
+		{
+			name:         "both wallet and currency",
+			body:         `{"wallet_id": "walletID", "currency": "USD", "max_amount": 100}`,
+			expectedBody: `{"error":"exactly one of wallet_id and currency is required"}`,
+		},
+		{
+			name:         "unsupported currency",
+			body:         `{"currency": "XXX", "max_amount": 100}`,
+			expectedBody: `{"error":"unsupported currency"}`,
+		},
+		{
+			name:         "invalid max_amount",
+			body:         `{"currency": "USD", "max_amount": 0}`,
+			expectedBody: `{"error":"invalid max_amount"}`,
+		},
+		{
+			name:         "invalid max_daily_amount",
+			body:         `{"currency": "USD", "max_daily_amount": -1}`,
+			expectedBody: `{"error":"invalid max_daily_amount"}`,
+		},
+		{
+			name:         "invalid max_hourly_count",
+			body:         `{"wallet_id": "walletID", "max_hourly_count": 0}`,
+			expectedBody: `{"error":"invalid max_hourly_count"}`,
+		},
+	}
+
+	for _, c := range validationErrors {
+		t.Run("validation error - "+c.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/admin/limits", bytes.NewReader([]byte(c.body)))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handlers.New(nil, nil, nil).HandleSetTransferLimits(rr, req, nil)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, c.expectedBody, rr.Body.String())
+		})
+	}
+
+	maxAmount := types.Money(10000)
+	maxHourlyCount := int64(5)
+
+	t.Run("storage error - wallet not found", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/admin/limits", bytes.NewReader([]byte(`{"wallet_id": "walletID", "max_amount": 10000}`)))
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			SetTransferLimits(gomock.Any(), types.TransferLimits{WalletID: "walletID", MaxAmount: &maxAmount}).
+			Times(1).
+			Return(&types.WalletNotFoundError{Field: "wallet_id"})
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleSetTransferLimits(rr, req, nil)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, `{"error":"wallet_id not found"}`, rr.Body.String())
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/admin/limits", bytes.NewReader([]byte(`{"currency": "USD", "max_amount": 10000}`)))
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			SetTransferLimits(gomock.Any(), types.TransferLimits{Currency: "USD", MaxAmount: &maxAmount}).
+			Times(1).
+			Return(errors.New("storage error"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleSetTransferLimits(rr, req, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, `{"error":"save to storage: storage error"}`, rr.Body.String())
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/admin/limits", bytes.NewReader([]byte(`{"currency": "USD", "max_amount": 10000, "max_hourly_count": 5}`)))
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			SetTransferLimits(gomock.Any(), types.TransferLimits{Currency: "USD", MaxAmount: &maxAmount, MaxHourlyCount: &maxHourlyCount}).
+			Times(1).
+			Return(nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleSetTransferLimits(rr, req, nil)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
+
+func TestHandleTransferLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("storage error", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/admin/limits", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			TransferLimits(gomock.Any()).
+			Times(1).
+			Return(nil, errors.New("storage error"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleTransferLimits(rr, req, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, `{"error":"fetch transfer limits: storage error"}`, rr.Body.String())
+	})
+
+	t.Run("happy path - no limits", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/admin/limits", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			TransferLimits(gomock.Any()).
+			Times(1).
+			Return(nil, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleTransferLimits(rr, req, nil)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"limits":[]}`, rr.Body.String())
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/admin/limits", nil)
+		require.NoError(t, err)
+
+		maxAmount := types.Money(10000)
+		maxHourlyCount := int64(5)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			TransferLimits(gomock.Any()).
+			Times(1).
+			Return([]types.TransferLimits{
+				{Currency: "USD", MaxAmount: &maxAmount},
+				{WalletID: "walletID", MaxHourlyCount: &maxHourlyCount},
+			}, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleTransferLimits(rr, req, nil)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"limits":[`+
+			`{"currency":"USD","max_amount":10000,"max_daily_amount":null,"max_hourly_count":null},`+
+			`{"wallet_id":"walletID","max_amount":null,"max_daily_amount":null,"max_hourly_count":5}]}`, rr.Body.String())
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -67,6 +68,76 @@ func TestHandleTransfer(t *testing.T) {
 		assert.Equal(t, `{"error":"invalid amount"}`, rr.Body.String())
 	})
 
+	t.Run("validation error - amount and decimal_amount", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","amount": 100,"decimal_amount": "1.00"}`))
+		req, err := http.NewRequest(http.MethodPost, "/transfer", body)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleTransfer(rr, req, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"amount and decimal_amount are mutually exclusive"}`, rr.Body.String())
+	})
+
+	t.Run("validation error - negative decimal_amount", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","decimal_amount": "-1.00"}`))
+		req, err := http.NewRequest(http.MethodPost, "/transfer", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Wallet(gomock.Any(), types.WalletID("wallet1")).
+			Times(1).
+			Return(types.Wallet{ID: "wallet1", Currency: "USD"}, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleTransfer(rr, req, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"invalid decimal_amount: amount must not be negative"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - decimal_amount from_wallet not found", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","decimal_amount": "1.00"}`))
+		req, err := http.NewRequest(http.MethodPost, "/transfer", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Wallet(gomock.Any(), types.WalletID("wallet1")).
+			Times(1).
+			Return(types.Wallet{}, &types.WalletNotFoundError{Field: "wallet"})
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleTransfer(rr, req, nil)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, `{"error":"from_wallet not found"}`, rr.Body.String())
+	})
+
+	t.Run("decimal_amount in currency of from_wallet", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","decimal_amount": "1.5"}`))
+		req, err := http.NewRequest(http.MethodPost, "/transfer", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Wallet(gomock.Any(), types.WalletID("wallet1")).
+			Times(1).
+			Return(types.Wallet{ID: "wallet1", Currency: "BHD"}, nil)
+		storageMock.EXPECT().
+			Transfer(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), types.Money(1500), nil).
+			Times(1).
+			Return(types.TransferID("transferID"), nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleTransfer(rr, req, nil)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"transfer_id":"transferID"}`, rr.Body.String())
+	})
+
 	t.Run("storage internal error", func(t *testing.T) {
 		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","amount": 100}`))
 		req, err := http.NewRequest(http.MethodPost, "/transfer", body)
@@ -74,9 +145,9 @@ func TestHandleTransfer(t *testing.T) {
 
 		storageMock := mocks.NewMockstorage(ctrl)
 		storageMock.EXPECT().
-			Transfer(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), 100).
+			Transfer(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), types.Money(100), nil).
 			Times(1).
-			Return(errors.New("storage error"))
+			Return(types.TransferID(""), errors.New("storage error"))
 
 		rr := httptest.NewRecorder()
 		handlers.New(nil, storageMock, nil).HandleTransfer(rr, req, nil)
@@ -85,6 +156,60 @@ func TestHandleTransfer(t *testing.T) {
 		assert.Equal(t, `{"error":"save to storage: storage error"}`, rr.Body.String())
 	})
 
+	t.Run("storage error - wallet not found", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/transfer", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Transfer(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), types.Money(100), nil).
+			Times(1).
+			Return(types.TransferID(""), errors.Wrap(&types.WalletNotFoundError{Field: "to_wallet"}, "rolled back"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleTransfer(rr, req, nil)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, `{"error":"to_wallet not found"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - wallet is frozen", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/transfer", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Transfer(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), types.Money(100), nil).
+			Times(1).
+			Return(types.TransferID(""), errors.Wrap(&types.WalletStatusError{Field: "from_wallet", Status: types.WalletStatusFrozen}, "rolled back"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleTransfer(rr, req, nil)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Equal(t, `{"error":"from_wallet is frozen"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - wallet is closed", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/transfer", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Transfer(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), types.Money(100), nil).
+			Times(1).
+			Return(types.TransferID(""), errors.Wrap(&types.WalletStatusError{Field: "to_wallet", Status: types.WalletStatusClosed}, "rolled back"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleTransfer(rr, req, nil)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Equal(t, `{"error":"to_wallet is closed"}`, rr.Body.String())
+	})
+
 	t.Run("storage error - insufficient funds in the account", func(t *testing.T) {
 		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","amount": 100}`))
 		req, err := http.NewRequest(http.MethodPost, "/transfer", body)
@@ -92,9 +217,9 @@ func TestHandleTransfer(t *testing.T) {
 
 		storageMock := mocks.NewMockstorage(ctrl)
 		storageMock.EXPECT().
-			Transfer(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), 100).
+			Transfer(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), types.Money(100), nil).
 			Times(1).
-			Return(types.ErrUnavailableBalance)
+			Return(types.TransferID(""), types.ErrUnavailableBalance)
 
 		rr := httptest.NewRecorder()
 		handlers.New(nil, storageMock, nil).HandleTransfer(rr, req, nil)
@@ -103,6 +228,74 @@ func TestHandleTransfer(t *testing.T) {
 		assert.Equal(t, `{"error":"insufficient funds in the account"}`, rr.Body.String())
 	})
 
+	t.Run("storage error - exchange rate not found", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/transfer", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Transfer(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), types.Money(100), nil).
+			Times(1).
+			Return(types.TransferID(""), errors.Wrap(types.ErrFXRateNotFound, "rolled back"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleTransfer(rr, req, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"exchange rate for currencies of wallets is not found"}`, rr.Body.String())
+	})
+
+	t.Run("validation error - too long idempotency key", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/transfer", body)
+		require.NoError(t, err)
+		req.Header.Set("Idempotency-Key", strings.Repeat("k", 256))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleTransfer(rr, req, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"idempotency key is too long"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - idempotency key reused", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/transfer", body)
+		require.NoError(t, err)
+		req.Header.Set("Idempotency-Key", "key")
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Transfer(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), types.Money(100), gomock.Not(gomock.Nil())).
+			Times(1).
+			Return(types.TransferID(""), types.ErrIdempotencyKeyReused)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleTransfer(rr, req, nil)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Equal(t, `{"error":"idempotency key is already used for another request"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - limit exceeded", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/transfer", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Transfer(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), types.Money(100), nil).
+			Times(1).
+			Return(types.TransferID(""), errors.Wrap(&types.LimitExceededError{Limit: types.LimitMaxHourlyCount, Remaining: 0}, "rolled back"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleTransfer(rr, req, nil)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Equal(t, `{"error":"transfer limit exceeded","limit":"max_hourly_count","remaining":0}`, rr.Body.String())
+	})
+
 	t.Run("happy path", func(t *testing.T) {
 		body := bytes.NewReader([]byte(`{"from_wallet": "wallet1", "to_wallet": "wallet2","amount": 100}`))
 		req, err := http.NewRequest(http.MethodPost, "/transfer", body)
@@ -110,13 +303,14 @@ func TestHandleTransfer(t *testing.T) {
 
 		storageMock := mocks.NewMockstorage(ctrl)
 		storageMock.EXPECT().
-			Transfer(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), 100).
+			Transfer(gomock.Any(), types.WalletID("wallet1"), types.WalletID("wallet2"), types.Money(100), nil).
 			Times(1).
-			Return(nil)
+			Return(types.TransferID("transferID"), nil)
 
 		rr := httptest.NewRecorder()
 		handlers.New(nil, storageMock, nil).HandleTransfer(rr, req, nil)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"transfer_id":"transferID"}`, rr.Body.String())
 	})
 }
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type walletSchedulesResponse struct {
+	WalletID  types.WalletID   `json:"wallet_id"`
+	Schedules []types.Schedule `json:"schedules"`
+}
+
+func (h *Handler) HandleWalletSchedules(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	walletID := params.ByName("wallet")
+	if walletID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("empty wallet id"))
+		return
+	}
+
+	schedules, err := h.s.WalletSchedules(r.Context(), types.WalletID(walletID))
+	if err != nil {
+		if errors.Cause(err) == types.ErrWalletNotFound {
+			writeErrorResponse(w, http.StatusNotFound, walletNotFoundError(err))
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "fetch schedules"))
+		return
+	}
+
+	if schedules == nil {
+		schedules = []types.Schedule{}
+	}
+
+	resp, err := json.Marshal(&walletSchedulesResponse{WalletID: types.WalletID(walletID), Schedules: schedules})
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "marshal response"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		log.WithError(err).Error("failed to write successful response")
+	}
+}
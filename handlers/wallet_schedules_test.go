@@ -0,0 +1,111 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleWalletSchedules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := []httprouter.Param{
+		{
+			Key:   "wallet",
+			Value: "walletID",
+		},
+	}
+
+	t.Run("storage error - wallet not found", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/wallet/walletID/schedules", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			WalletSchedules(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(nil, &types.WalletNotFoundError{Field: "wallet"})
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleWalletSchedules(rr, req, params)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, `{"error":"wallet not found"}`, rr.Body.String())
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/wallet/walletID/schedules", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			WalletSchedules(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(nil, errors.New("storage error"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleWalletSchedules(rr, req, params)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, `{"error":"fetch schedules: storage error"}`, rr.Body.String())
+	})
+
+	t.Run("happy path - no schedules", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/wallet/walletID/schedules", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			WalletSchedules(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(nil, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleWalletSchedules(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"wallet_id":"walletID","schedules":[]}`, rr.Body.String())
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/wallet/walletID/schedules", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			WalletSchedules(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return([]types.Schedule{
+				{
+					ID:         "scheduleID",
+					FromWallet: "walletID",
+					ToWallet:   "wallet2",
+					Amount:     100,
+					Recurrence: types.ScheduleRecurrenceOnce,
+					StartAt:    time.Date(2022, time.January, 3, 10, 0, 0, 0, time.UTC),
+					Timezone:   "UTC",
+					Status:     types.ScheduleStatusCompleted,
+					CreatedAt:  time.Date(2022, time.January, 2, 3, 4, 5, 0, time.UTC),
+				},
+			}, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleWalletSchedules(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"wallet_id":"walletID","schedules":[{"schedule_id":"scheduleID","from_wallet":"walletID",`+
+			`"to_wallet":"wallet2","amount":100,"recurrence":"once","start_at":"2022-01-03T10:00:00Z","timezone":"UTC","next_run_at":null,`+
+			`"status":"completed","created_at":"2022-01-02T03:04:05Z"}]}`, rr.Body.String())
+	})
+}
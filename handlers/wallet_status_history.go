@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type walletStatusHistoryResponse struct {
+	WalletID types.WalletID             `json:"wallet_id"`
+	Changes  []types.WalletStatusChange `json:"changes"`
+}
+
+func (h *Handler) HandleWalletStatusHistory(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	walletID := params.ByName("wallet")
+	if walletID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("empty wallet id"))
+		return
+	}
+
+	changes, err := h.s.WalletStatusHistory(r.Context(), types.WalletID(walletID))
+	if err != nil {
+		if errors.Cause(err) == types.ErrWalletNotFound {
+			writeErrorResponse(w, http.StatusNotFound, walletNotFoundError(err))
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "fetch wallet status history"))
+		return
+	}
+
+	if changes == nil {
+		changes = []types.WalletStatusChange{}
+	}
+
+	resp, err := json.Marshal(&walletStatusHistoryResponse{WalletID: types.WalletID(walletID), Changes: changes})
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "marshal response"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		log.WithError(err).Error("failed to write successful response")
+	}
+}
@@ -0,0 +1,105 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleWalletStatusHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := []httprouter.Param{
+		{
+			Key:   "wallet",
+			Value: "walletID",
+		},
+	}
+
+	t.Run("storage error", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/admin/wallet/walletID/status/history", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			WalletStatusHistory(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(nil, errors.New("storage error"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleWalletStatusHistory(rr, req, params)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, `{"error":"fetch wallet status history: storage error"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - wallet not found", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/admin/wallet/walletID/status/history", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			WalletStatusHistory(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(nil, &types.WalletNotFoundError{Field: "wallet"})
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleWalletStatusHistory(rr, req, params)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, `{"error":"wallet not found"}`, rr.Body.String())
+	})
+
+	t.Run("happy path - no changes", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/admin/wallet/walletID/status/history", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			WalletStatusHistory(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return(nil, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleWalletStatusHistory(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"wallet_id":"walletID","changes":[]}`, rr.Body.String())
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/admin/wallet/walletID/status/history", nil)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			WalletStatusHistory(gomock.Any(), types.WalletID("walletID")).
+			Times(1).
+			Return([]types.WalletStatusChange{
+				{
+					WalletID:   "walletID",
+					FromStatus: types.WalletStatusActive,
+					ToStatus:   types.WalletStatusFrozen,
+					Reason:     "investigation",
+					CreatedAt:  time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC),
+				},
+			}, nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleWalletStatusHistory(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"wallet_id":"walletID","changes":[{"from_status":"active","to_status":"frozen","reason":"investigation","created_at":"2030-01-01T10:00:00Z"}]}`, rr.Body.String())
+	})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+func (h *Handler) HandleWithdraw(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	walletID := params.ByName("wallet")
+	if walletID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("empty wallet id"))
+		return
+	}
+
+	amount, idemKey, ok := h.parseWalletAmount(w, r, types.WalletID(walletID), "withdraw:"+walletID)
+	if !ok {
+		return
+	}
+
+	if err := h.s.Withdraw(r.Context(), types.WalletID(walletID), amount, idemKey); err != nil {
+		switch errors.Cause(err) {
+		case types.ErrWalletNotFound:
+			writeErrorResponse(w, http.StatusNotFound, walletNotFoundError(err))
+			return
+		case types.ErrWalletFrozen:
+			writeErrorResponse(w, http.StatusForbidden, walletStatusError(err))
+			return
+		case types.ErrWalletClosed:
+			writeErrorResponse(w, http.StatusConflict, walletStatusError(err))
+			return
+		case types.ErrUnavailableBalance:
+			writeErrorResponse(w, http.StatusBadRequest, types.ErrUnavailableBalance)
+			return
+		case types.ErrAmountOverflow:
+			writeErrorResponse(w, http.StatusBadRequest, types.ErrAmountOverflow)
+			return
+		case types.ErrLimitExceeded:
+			writeLimitExceededResponse(w, err)
+			return
+		case types.ErrIdempotencyKeyReused:
+			writeErrorResponse(w, http.StatusConflict, types.ErrIdempotencyKeyReused)
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Wrap(err, "save to storage"))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,123 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/justteddy/wallet/handlers"
+	"github.com/justteddy/wallet/handlers/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleWithdraw(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := []httprouter.Param{
+		{
+			Key:   "wallet",
+			Value: "walletID",
+		},
+	}
+
+	t.Run("validation error - empty wallet", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/withdraw", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleWithdraw(rr, req, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"empty wallet id"}`, rr.Body.String())
+	})
+
+	t.Run("validation error - invalid amount", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"amount": 0}`))
+		req, err := http.NewRequest(http.MethodPost, "/withdraw/walletID", body)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, nil, nil).HandleWithdraw(rr, req, params)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"invalid amount"}`, rr.Body.String())
+	})
+
+	t.Run("storage internal error", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/withdraw/walletID", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Withdraw(gomock.Any(), types.WalletID("walletID"), types.Money(100), nil).
+			Times(1).
+			Return(errors.New("storage error"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleWithdraw(rr, req, params)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, `{"error":"save to storage: storage error"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - insufficient funds in the account", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/withdraw/walletID", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Withdraw(gomock.Any(), types.WalletID("walletID"), types.Money(100), nil).
+			Times(1).
+			Return(errors.Wrap(types.ErrUnavailableBalance, "storage"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleWithdraw(rr, req, params)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"error":"insufficient funds in the account"}`, rr.Body.String())
+	})
+
+	t.Run("storage error - limit exceeded", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/withdraw/walletID", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Withdraw(gomock.Any(), types.WalletID("walletID"), types.Money(100), nil).
+			Times(1).
+			Return(errors.Wrap(&types.LimitExceededError{Limit: types.LimitMaxAmount, Remaining: 50}, "rolled back"))
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleWithdraw(rr, req, params)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Equal(t, `{"error":"transfer limit exceeded","limit":"max_amount","remaining":50}`, rr.Body.String())
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		body := bytes.NewReader([]byte(`{"amount": 100}`))
+		req, err := http.NewRequest(http.MethodPost, "/withdraw/walletID", body)
+		require.NoError(t, err)
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			Withdraw(gomock.Any(), types.WalletID("walletID"), types.Money(100), nil).
+			Times(1).
+			Return(nil)
+
+		rr := httptest.NewRecorder()
+		handlers.New(nil, storageMock, nil).HandleWithdraw(rr, req, params)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
@@ -1,21 +1,213 @@
 CREATE DATABASE wallets;
 \connect wallets;
 
+CREATE TYPE wallet_status AS ENUM ('active', 'frozen', 'closed');
+
 CREATE TABLE IF NOT EXISTS wallet (
     id VARCHAR(64) PRIMARY KEY,
-    balance INTEGER NOT NULL,
-    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+    -- ISO 4217 currency code, balance is stored in minor units of the currency
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    -- only active wallets take part in operations
+    status wallet_status NOT NULL DEFAULT 'active',
+    balance BIGINT NOT NULL,
+    -- balance can go below zero down to -overdraft_limit
+    overdraft_limit BIGINT NOT NULL DEFAULT 0 CHECK (overdraft_limit >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- audit history of wallet status changes
+CREATE TABLE IF NOT EXISTS wallet_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    wallet_id VARCHAR(64) NOT NULL REFERENCES wallet (id),
+    from_status wallet_status NOT NULL,
+    to_status wallet_status NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX wallet_status_history_wallet_idx ON wallet_status_history USING BTREE (wallet_id, id);
+
+CREATE TYPE operation AS ENUM ('deposit', 'withdraw', 'reversal');
+
+CREATE TYPE journal_entry AS ENUM ('deposit', 'withdraw', 'transfer', 'reversal');
+
+-- double-entry ledger, every movement of money is a journal entry with postings which sum to zero.
+-- Postings to wallet accounts use wallet id as account id, external money flows use system accounts.
+-- Postings of the entry sum to zero in every currency.
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id BIGSERIAL PRIMARY KEY,
+    entry_type journal_entry NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
 
-CREATE TYPE operation AS ENUM ('deposit', 'withdraw');
+CREATE TABLE IF NOT EXISTS postings (
+    id BIGSERIAL PRIMARY KEY,
+    entry_id BIGINT NOT NULL REFERENCES journal_entries (id),
+    account_id VARCHAR(64) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount <> 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX postings_entry_idx ON postings USING BTREE (entry_id);
+CREATE INDEX postings_account_idx ON postings USING HASH (account_id);
+
+CREATE FUNCTION check_journal_entry_balanced() RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM postings WHERE entry_id = NEW.entry_id GROUP BY currency HAVING SUM(amount) <> 0
+    ) THEN
+        RAISE EXCEPTION 'journal entry % is not balanced', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- checked on commit, when all postings of the entry are written
+CREATE CONSTRAINT TRIGGER postings_balanced
+    AFTER INSERT OR UPDATE ON postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION check_journal_entry_balanced();
+
+-- versioned exchange rates, the rate of the pair is effective since effective_from until the next version
+CREATE TABLE IF NOT EXISTS fx_rates (
+    id BIGSERIAL PRIMARY KEY,
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate NUMERIC(24, 12) NOT NULL CHECK (rate > 0),
+    effective_from TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (base_currency, quote_currency, effective_from)
+);
 
 CREATE TABLE IF NOT EXISTS operations (
     id BIGSERIAL PRIMARY KEY,
+    -- identifier of the operation exposed to clients, it's random, so it doesn't reveal the number of operations
+    public_id UUID NOT NULL UNIQUE,
     wallet_id VARCHAR(64) NOT NULL REFERENCES wallet (id),
     operation_type operation NOT NULL,
-    amount INTEGER NOT NULL,
-    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    transfer_id UUID,
+    counterparty_wallet_id VARCHAR(64) REFERENCES wallet (id),
+    entry_id BIGINT NOT NULL REFERENCES journal_entries (id),
+    reversal_of BIGINT REFERENCES operations (id),
+    -- conversion of cross-currency transfer, the same on both legs
+    source_amount BIGINT,
+    source_currency CHAR(3),
+    destination_amount BIGINT,
+    destination_currency CHAR(3),
+    fx_rate NUMERIC(24, 12),
+    fx_rate_id BIGINT REFERENCES fx_rates (id),
+    -- balance of the wallet after the operation, so reports don't sum the whole history of the wallet
+    balance_after BIGINT NOT NULL,
+    -- credit of the wallet used after the operation (negative available balance) and its overdraft limit at that time
+    used_credit BIGINT NOT NULL DEFAULT 0,
+    overdraft_limit BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
 
 CREATE INDEX wallet_idx ON operations USING HASH (wallet_id);
+CREATE INDEX transfer_idx ON operations USING HASH (transfer_id);
+-- operation can be reversed partially several times, its reversals are summed to check the rest of its amount
+CREATE INDEX reversal_of_idx ON operations USING BTREE (reversal_of);
 CREATE INDEX created_at_idx ON operations USING BTREE (created_at);
+-- keyset pagination of reports
+CREATE INDEX operations_wallet_created_at_id_idx ON operations USING BTREE (wallet_id, created_at, id);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    request_hash VARCHAR(64) NOT NULL,
+    result VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idempotency_keys_created_at_idx ON idempotency_keys USING BTREE (created_at);
+
+-- limits of outgoing transfers of the wallet or default limits of wallets in the currency,
+-- limits of the wallet replace default ones as a whole, NULL limit isn't checked
+CREATE TABLE IF NOT EXISTS transfer_limits (
+    id BIGSERIAL PRIMARY KEY,
+    wallet_id VARCHAR(64) REFERENCES wallet (id),
+    currency CHAR(3),
+    max_amount BIGINT CHECK (max_amount > 0),
+    max_daily_amount BIGINT CHECK (max_daily_amount > 0),
+    max_hourly_count BIGINT CHECK (max_hourly_count > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((wallet_id IS NULL) <> (currency IS NULL))
+);
+
+CREATE UNIQUE INDEX transfer_limits_wallet_idx ON transfer_limits USING BTREE (wallet_id) WHERE wallet_id IS NOT NULL;
+CREATE UNIQUE INDEX transfer_limits_currency_idx ON transfer_limits USING BTREE (currency) WHERE currency IS NOT NULL;
+-- outgoing transfers of the wallet are counted by limits
+CREATE INDEX operations_outgoing_transfers_idx ON operations USING BTREE (wallet_id, created_at)
+    WHERE operation_type = 'withdraw';
+
+-- transfers from one wallet executed in one transaction, every leg is a separate transfer
+CREATE TABLE IF NOT EXISTS transfer_batches (
+    id UUID PRIMARY KEY,
+    from_wallet_id VARCHAR(64) NOT NULL REFERENCES wallet (id),
+    currency CHAR(3) NOT NULL,
+    total_amount BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS transfer_batch_legs (
+    batch_id UUID NOT NULL REFERENCES transfer_batches (id),
+    leg_index INT NOT NULL,
+    to_wallet_id VARCHAR(64) NOT NULL REFERENCES wallet (id),
+    amount BIGINT NOT NULL,
+    transfer_id UUID NOT NULL,
+    PRIMARY KEY (batch_id, leg_index)
+);
+
+CREATE TYPE hold_status AS ENUM ('active', 'captured', 'voided');
+
+-- hold reserves amount of wallet balance, active holds are expired after expires_at
+CREATE TABLE IF NOT EXISTS holds (
+    id UUID PRIMARY KEY,
+    wallet_id VARCHAR(64) NOT NULL REFERENCES wallet (id),
+    to_wallet_id VARCHAR(64) NOT NULL REFERENCES wallet (id),
+    amount BIGINT NOT NULL,
+    status hold_status NOT NULL,
+    captured_amount BIGINT,
+    transfer_id UUID,
+    expires_at TIMESTAMPTZ NOT NULL,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX holds_active_wallet_idx ON holds USING BTREE (wallet_id) WHERE status = 'active';
+
+CREATE TYPE schedule_recurrence AS ENUM ('once', 'daily', 'weekly', 'monthly');
+
+CREATE TYPE schedule_status AS ENUM ('active', 'completed', 'cancelled');
+
+-- scheduled transfers, active schedules are executed by the scheduler at next_run_at
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id UUID PRIMARY KEY,
+    from_wallet_id VARCHAR(64) NOT NULL REFERENCES wallet (id),
+    to_wallet_id VARCHAR(64) NOT NULL REFERENCES wallet (id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    recurrence schedule_recurrence NOT NULL,
+    start_at TIMESTAMPTZ NOT NULL,
+    -- IANA name or UTC offset, occurrences of recurring schedules are calculated in this timezone
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    next_run_at TIMESTAMPTZ,
+    status schedule_status NOT NULL DEFAULT 'active',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX scheduled_transfers_from_wallet_idx ON scheduled_transfers USING BTREE (from_wallet_id, created_at);
+CREATE INDEX scheduled_transfers_due_idx ON scheduled_transfers USING BTREE (next_run_at) WHERE status = 'active';
+
+-- executions of scheduled transfers, failed execution has error instead of transfer
+CREATE TABLE IF NOT EXISTS scheduled_transfer_runs (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id UUID NOT NULL REFERENCES scheduled_transfers (id),
+    run_at TIMESTAMPTZ NOT NULL,
+    transfer_id UUID,
+    error TEXT,
+    executed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (schedule_id, run_at)
+);
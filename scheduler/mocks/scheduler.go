@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: scheduler.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/justteddy/wallet/types"
+)
+
+// Mockstorage is a mock of storage interface.
+type Mockstorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockstorageMockRecorder
+}
+
+// MockstorageMockRecorder is the mock recorder for Mockstorage.
+type MockstorageMockRecorder struct {
+	mock *Mockstorage
+}
+
+// NewMockstorage creates a new mock instance.
+func NewMockstorage(ctrl *gomock.Controller) *Mockstorage {
+	mock := &Mockstorage{ctrl: ctrl}
+	mock.recorder = &MockstorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockstorage) EXPECT() *MockstorageMockRecorder {
+	return m.recorder
+}
+
+// DueSchedules mocks base method.
+func (m *Mockstorage) DueSchedules(ctx context.Context, now time.Time, limit int) ([]types.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DueSchedules", ctx, now, limit)
+	ret0, _ := ret[0].([]types.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DueSchedules indicates an expected call of DueSchedules.
+func (mr *MockstorageMockRecorder) DueSchedules(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DueSchedules", reflect.TypeOf((*Mockstorage)(nil).DueSchedules), ctx, now, limit)
+}
+
+// RunSchedule mocks base method.
+func (m *Mockstorage) RunSchedule(ctx context.Context, schedule types.Schedule, next *time.Time) (types.ScheduleRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunSchedule", ctx, schedule, next)
+	ret0, _ := ret[0].(types.ScheduleRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunSchedule indicates an expected call of RunSchedule.
+func (mr *MockstorageMockRecorder) RunSchedule(ctx, schedule, next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunSchedule", reflect.TypeOf((*Mockstorage)(nil).RunSchedule), ctx, schedule, next)
+}
@@ -0,0 +1,152 @@
+package scheduler
+
+//go:generate mockgen -source=scheduler.go -destination=mocks/scheduler.go -package=mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// batchSize is the number of due schedules fetched at once
+const batchSize = 100
+
+type storage interface {
+	// DueSchedules fetches up to limit active schedules which next run is not after now
+	DueSchedules(ctx context.Context, now time.Time, limit int) ([]types.Schedule, error)
+	// RunSchedule transfers the next run of the schedule, records the run and moves the schedule to next run
+	// in one transaction, nil next run completes the schedule. The run is transferred only once,
+	// types.ErrScheduleRunClaimed is returned if it's executed by another process.
+	RunSchedule(ctx context.Context, schedule types.Schedule, next *time.Time) (types.ScheduleRun, error)
+}
+
+// Scheduler executes due scheduled transfers in background
+type Scheduler struct {
+	s        storage
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New creates scheduler which looks for due schedules every interval
+func New(s storage, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		s:        s,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start starts the worker loop, it must be called only once
+func (sc *Scheduler) Start() {
+	go func() {
+		defer close(sc.done)
+
+		ticker := time.NewTicker(sc.interval)
+		defer ticker.Stop()
+
+		for {
+			// the run isn't interrupted by Stop, so started transfers are recorded
+			if _, err := sc.RunDue(context.Background(), time.Now().UTC()); err != nil {
+				log.WithError(err).Error("run due schedules")
+			}
+
+			select {
+			case <-sc.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the worker loop and waits until the schedule being executed is recorded or ctx is done
+func (sc *Scheduler) Stop(ctx context.Context) error {
+	close(sc.stop)
+
+	select {
+	case <-sc.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "wait for scheduler")
+	}
+}
+
+// RunDue executes schedules which are due at now and returns the number of executed ones.
+// Every run of the schedule is claimed in the transaction of its transfer, so it's transferred only once
+// even if several schedulers execute it concurrently. Missed occurrences of recurring schedules are skipped.
+// Schedule which execution failed is retried on the next call and doesn't block the rest of due schedules.
+func (sc *Scheduler) RunDue(ctx context.Context, now time.Time) (int, error) {
+	var executed int
+	// failed schedules are still due, so they are fetched again in addition to the batch and skipped,
+	// the same goes for schedules executed by another scheduler in the meantime
+	skipped := make(map[types.ScheduleID]struct{})
+	for {
+		limit := batchSize + len(skipped)
+		schedules, err := sc.s.DueSchedules(ctx, now, limit)
+		if err != nil {
+			return executed, err
+		}
+
+		for _, schedule := range schedules {
+			if sc.stopped() {
+				return executed, nil
+			}
+
+			if _, ok := skipped[schedule.ID]; ok {
+				continue
+			}
+
+			if err := sc.run(ctx, schedule, now); err != nil {
+				if errors.Cause(err) != types.ErrScheduleRunClaimed {
+					log.WithError(err).WithField("schedule_id", schedule.ID).Error("run schedule")
+				}
+				skipped[schedule.ID] = struct{}{}
+				continue
+			}
+			executed++
+		}
+
+		if len(schedules) < limit {
+			return executed, nil
+		}
+	}
+}
+
+func (sc *Scheduler) stopped() bool {
+	select {
+	case <-sc.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// run transfers the next occurrence of the schedule and moves the schedule to the occurrence after now,
+// transfer rejected by storage is recorded as failed run, the occurrence is retried later if it failed for other reasons
+func (sc *Scheduler) run(ctx context.Context, schedule types.Schedule, now time.Time) error {
+	loc, err := types.LoadScheduleLocation(schedule.Timezone)
+	if err != nil {
+		return errors.Wrap(err, "load timezone")
+	}
+
+	var next *time.Time
+	if nextRunAt, ok := schedule.Recurrence.Next(schedule.StartAt.In(loc), now); ok {
+		nextRunAt = nextRunAt.UTC()
+		next = &nextRunAt
+	}
+
+	run, err := sc.s.RunSchedule(ctx, schedule, next)
+	if err != nil {
+		return errors.Wrap(err, "run schedule")
+	}
+
+	if run.Error != "" {
+		log.WithField("schedule_id", schedule.ID).WithField("error", run.Error).Warn("scheduled transfer failed")
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package scheduler_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/justteddy/wallet/scheduler"
+	"github.com/justteddy/wallet/scheduler/mocks"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2022, time.March, 10, 12, 0, 0, 0, time.UTC)
+	runAt := time.Date(2022, time.March, 10, 10, 0, 0, 0, time.UTC)
+	start := time.Date(2022, time.March, 3, 10, 0, 0, 0, time.UTC)
+	nextRunAt := time.Date(2022, time.March, 17, 10, 0, 0, 0, time.UTC)
+
+	weekly := types.Schedule{
+		ID:         "weekly",
+		FromWallet: "wallet1",
+		ToWallet:   "wallet2",
+		Amount:     100,
+		Recurrence: types.ScheduleRecurrenceWeekly,
+		StartAt:    start,
+		NextRunAt:  &runAt,
+		Status:     types.ScheduleStatusActive,
+	}
+	once := types.Schedule{
+		ID:         "once",
+		FromWallet: "wallet1",
+		ToWallet:   "wallet3",
+		Amount:     500,
+		Recurrence: types.ScheduleRecurrenceOnce,
+		StartAt:    runAt,
+		NextRunAt:  &runAt,
+		Status:     types.ScheduleStatusActive,
+	}
+
+	t.Run("storage error", func(t *testing.T) {
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			DueSchedules(gomock.Any(), now, 100).
+			Times(1).
+			Return(nil, errors.New("storage error"))
+
+		executed, err := scheduler.New(storageMock, time.Minute).RunDue(context.Background(), now)
+		assert.EqualError(t, err, "storage error")
+		assert.Equal(t, 0, executed)
+	})
+
+	t.Run("failed schedule doesn't block the rest of the batch", func(t *testing.T) {
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			DueSchedules(gomock.Any(), now, 100).
+			Times(1).
+			Return([]types.Schedule{weekly, once}, nil)
+		storageMock.EXPECT().
+			RunSchedule(gomock.Any(), weekly, &nextRunAt).
+			Times(1).
+			Return(types.ScheduleRun{}, errors.New("connection refused"))
+		storageMock.EXPECT().
+			RunSchedule(gomock.Any(), once, nil).
+			Times(1).
+			Return(types.ScheduleRun{ScheduleID: "once", RunAt: runAt, TransferID: "transferID"}, nil)
+
+		executed, err := scheduler.New(storageMock, time.Minute).RunDue(context.Background(), now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, executed)
+	})
+
+	t.Run("failed schedule is skipped in the next batch", func(t *testing.T) {
+		batch := make([]types.Schedule, 0, 100)
+		for i := 0; i < 100; i++ {
+			schedule := once
+			schedule.ID = types.ScheduleID(fmt.Sprintf("once%d", i))
+			batch = append(batch, schedule)
+		}
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		gomock.InOrder(
+			storageMock.EXPECT().
+				DueSchedules(gomock.Any(), now, 100).
+				Times(1).
+				Return(batch, nil),
+			// the failed schedule is still due, it's fetched again together with the rest of due schedules
+			storageMock.EXPECT().
+				DueSchedules(gomock.Any(), now, 101).
+				Times(1).
+				Return([]types.Schedule{batch[0], weekly}, nil),
+		)
+		storageMock.EXPECT().
+			RunSchedule(gomock.Any(), gomock.Any(), gomock.Any()).
+			Times(101).
+			DoAndReturn(func(_ context.Context, schedule types.Schedule, _ *time.Time) (types.ScheduleRun, error) {
+				if schedule.ID == "once0" {
+					return types.ScheduleRun{}, errors.New("connection refused")
+				}
+				return types.ScheduleRun{ScheduleID: schedule.ID, RunAt: runAt, TransferID: "transferID"}, nil
+			})
+
+		executed, err := scheduler.New(storageMock, time.Minute).RunDue(context.Background(), now)
+		require.NoError(t, err)
+		assert.Equal(t, 100, executed)
+	})
+
+	t.Run("schedule claimed by another scheduler is skipped", func(t *testing.T) {
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			DueSchedules(gomock.Any(), now, 100).
+			Times(1).
+			Return([]types.Schedule{weekly, once}, nil)
+		storageMock.EXPECT().
+			RunSchedule(gomock.Any(), weekly, &nextRunAt).
+			Times(1).
+			Return(types.ScheduleRun{}, errors.Wrap(types.ErrScheduleRunClaimed, "detected error and rolled transaction back"))
+		storageMock.EXPECT().
+			RunSchedule(gomock.Any(), once, nil).
+			Times(1).
+			Return(types.ScheduleRun{ScheduleID: "once", RunAt: runAt, TransferID: "transferID"}, nil)
+
+		executed, err := scheduler.New(storageMock, time.Minute).RunDue(context.Background(), now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, executed)
+	})
+
+	t.Run("happy path - successful and failed runs are recorded", func(t *testing.T) {
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			DueSchedules(gomock.Any(), now, 100).
+			Times(1).
+			Return([]types.Schedule{weekly, once}, nil)
+		storageMock.EXPECT().
+			RunSchedule(gomock.Any(), weekly, &nextRunAt).
+			Times(1).
+			Return(types.ScheduleRun{ScheduleID: "weekly", RunAt: runAt, TransferID: "transferID"}, nil)
+		// the schedule of a single transfer is completed
+		storageMock.EXPECT().
+			RunSchedule(gomock.Any(), once, nil).
+			Times(1).
+			Return(types.ScheduleRun{ScheduleID: "once", RunAt: runAt, Error: "insufficient funds in the account"}, nil)
+
+		executed, err := scheduler.New(storageMock, time.Minute).RunDue(context.Background(), now)
+		require.NoError(t, err)
+		assert.Equal(t, 2, executed)
+	})
+
+	t.Run("happy path - monthly schedule in timezone with positive offset", func(t *testing.T) {
+		loc, err := types.LoadScheduleLocation("+03:00")
+		require.NoError(t, err)
+
+		// 1st of month at 01:00 in the schedule timezone is the last day of the previous month in UTC
+		runAt := time.Date(2022, time.April, 1, 1, 0, 0, 0, loc).UTC()
+		nextRunAt := time.Date(2022, time.May, 1, 1, 0, 0, 0, loc).UTC()
+		monthly := types.Schedule{
+			ID:         "monthly",
+			FromWallet: "wallet1",
+			ToWallet:   "wallet2",
+			Amount:     100,
+			Recurrence: types.ScheduleRecurrenceMonthly,
+			StartAt:    time.Date(2022, time.March, 1, 1, 0, 0, 0, loc).UTC(),
+			Timezone:   "+03:00",
+			NextRunAt:  &runAt,
+			Status:     types.ScheduleStatusActive,
+		}
+
+		storageMock := mocks.NewMockstorage(ctrl)
+		storageMock.EXPECT().
+			DueSchedules(gomock.Any(), runAt, 100).
+			Times(1).
+			Return([]types.Schedule{monthly}, nil)
+		storageMock.EXPECT().
+			RunSchedule(gomock.Any(), monthly, &nextRunAt).
+			Times(1).
+			Return(types.ScheduleRun{ScheduleID: "monthly", RunAt: runAt, TransferID: "transferID"}, nil)
+
+		executed, err := scheduler.New(storageMock, time.Minute).RunDue(context.Background(), runAt)
+		require.NoError(t, err)
+		assert.Equal(t, 1, executed)
+	})
+}
+
+func TestStartStop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storageMock := mocks.NewMockstorage(ctrl)
+	storageMock.EXPECT().
+		DueSchedules(gomock.Any(), gomock.Any(), 100).
+		MinTimes(1).
+		Return(nil, nil)
+
+	sc := scheduler.New(storageMock, time.Millisecond)
+	sc.Start()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, sc.Stop(ctx))
+}
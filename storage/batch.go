@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+// BatchTransfer executes every leg of the batch as a transfer from fromWallet in one transaction
+// and returns the batch with transfer identifiers of the legs. Any failed leg rolls the whole batch back.
+// Wallets of the batch are locked in order of their identifiers, so concurrent batches can't deadlock.
+// The request with already used idempotency key is applied only once and returns the original batch.
+func (s *storage) BatchTransfer(ctx context.Context, fromWallet types.WalletID, legs []types.BatchLeg, idemKey *types.IdempotencyKey) (types.TransferBatch, error) {
+	var batch types.TransferBatch
+	err := s.runTx(ctx, "batch_transfer", func(tx *sqlx.Tx) error {
+		replayed, result, err := s.acquireIdempotencyKey(ctx, tx, idemKey)
+		if err != nil {
+			return err
+		}
+		if replayed {
+			batch, err = transferBatch(ctx, tx, types.BatchID(result))
+			return err
+		}
+
+		wallets := make([]string, 0, len(legs)+1)
+		wallets = append(wallets, string(fromWallet))
+		for _, leg := range legs {
+			wallets = append(wallets, string(leg.ToWallet))
+		}
+
+		locked, err := lockWallets(ctx, tx, wallets)
+		if err != nil {
+			return err
+		}
+
+		from, ok := locked[fromWallet]
+		if !ok {
+			return &types.WalletNotFoundError{Field: "from_wallet"}
+		}
+		if err := from.Status.CheckActive("from_wallet"); err != nil {
+			return err
+		}
+
+		var total types.Money
+		for i, leg := range legs {
+			field := fmt.Sprintf("legs[%d].to_wallet", i)
+			to, ok := locked[leg.ToWallet]
+			if !ok {
+				return &types.WalletNotFoundError{Field: field}
+			}
+			if err := to.Status.CheckActive(field); err != nil {
+				return err
+			}
+
+			if total, err = total.Add(leg.Amount); err != nil {
+				return err
+			}
+		}
+
+		// the total is checked before any leg, so the batch fails as a whole if the source can't pay it
+		held, err := heldAmount(ctx, tx, fromWallet)
+		if err != nil {
+			return err
+		}
+		if err := checkDebit(from.Balance, held, from.OverdraftLimit, total); err != nil {
+			return err
+		}
+
+		batchID, err := newUUID()
+		if err != nil {
+			return errors.Wrap(err, "generate batch id")
+		}
+
+		batch = types.TransferBatch{
+			ID:          types.BatchID(batchID),
+			FromWallet:  fromWallet,
+			Currency:    from.Currency,
+			TotalAmount: total,
+			Legs:        make([]types.BatchLeg, 0, len(legs)),
+		}
+		if err := tx.QueryRowContext(ctx, queryInsertTransferBatch, batchID, fromWallet, from.Currency, total).Scan(&batch.CreatedAt); err != nil {
+			return errors.Wrap(err, "create batch")
+		}
+
+		for i, leg := range legs {
+			transferID, err := s.transfer(ctx, tx, fromWallet, leg.ToWallet, leg.Amount)
+			if err != nil {
+				return &types.BatchLegError{Index: i, Err: err}
+			}
+
+			if _, err := tx.ExecContext(ctx, queryInsertTransferBatchLeg, batchID, i, leg.ToWallet, leg.Amount, transferID); err != nil {
+				return errors.Wrap(err, "create batch leg")
+			}
+
+			leg.TransferID = transferID
+			batch.Legs = append(batch.Legs, leg)
+		}
+
+		return saveIdempotencyResult(ctx, tx, idemKey, batchID)
+	})
+	if err != nil {
+		return types.TransferBatch{}, err
+	}
+
+	return batch, nil
+}
+
+// TransferBatch fetches the batch with its legs in the original order
+func (s *storage) TransferBatch(ctx context.Context, batchID types.BatchID) (types.TransferBatch, error) {
+	return transferBatch(ctx, s.conn, batchID)
+}
+
+func transferBatch(ctx context.Context, q sqlx.QueryerContext, batchID types.BatchID) (types.TransferBatch, error) {
+	var batch types.TransferBatch
+	if err := sqlx.GetContext(ctx, q, &batch, querySelectTransferBatch, batchID); err != nil {
+		if err == sql.ErrNoRows {
+			return batch, types.ErrBatchNotFound
+		}
+		return batch, errors.Wrap(err, "select batch")
+	}
+
+	if err := sqlx.SelectContext(ctx, q, &batch.Legs, querySelectTransferBatchLegs, batchID); err != nil {
+		return batch, errors.Wrap(err, "select batch legs")
+	}
+
+	return batch, nil
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/justteddy/wallet/fx"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+// SaveRates saves new versions of exchange rates and returns the number of saved ones.
+// Saved versions are immutable, the same rate with already saved pair and effective time is skipped,
+// another rate for them fails with ErrFXRateConflict.
+func (s *storage) SaveRates(ctx context.Context, rates []types.FXRate) (int64, error) {
+	var saved int64
+	err := s.runTx(ctx, "save_rates", func(tx *sqlx.Tx) error {
+		saved = 0
+		for _, rate := range rates {
+			res, err := tx.ExecContext(ctx, queryInsertFXRate, rate.BaseCurrency, rate.QuoteCurrency, rate.Rate, rate.EffectiveFrom)
+			if err != nil {
+				return errors.Wrap(err, "insert rate")
+			}
+
+			inserted, err := res.RowsAffected()
+			if err != nil {
+				return errors.Wrap(err, "rows affected")
+			}
+			if inserted == 0 {
+				var same bool
+				if err := tx.GetContext(ctx, &same, querySelectSameFXRate, rate.BaseCurrency, rate.QuoteCurrency, rate.EffectiveFrom, rate.Rate); err != nil {
+					return errors.Wrap(err, "select saved rate")
+				}
+				if !same {
+					return errors.Wrapf(types.ErrFXRateConflict, "%s/%s from %s", rate.BaseCurrency, rate.QuoteCurrency, rate.EffectiveFrom.Format(time.RFC3339))
+				}
+			}
+			saved += inserted
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return saved, nil
+}
+
+// convert converts amount from one currency to another by the effective exchange rate with spread
+func (s *storage) convert(ctx context.Context, tx *sqlx.Tx, amount types.Money, from, to string) (*types.Conversion, error) {
+	var rate types.FXRate
+	if err := tx.GetContext(ctx, &rate, querySelectEffectiveFXRate, from, to); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, types.ErrFXRateNotFound
+		}
+		return nil, errors.Wrap(err, "select exchange rate")
+	}
+
+	midRate, err := fx.ParseRate(rate.Rate)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse exchange rate %d", rate.ID)
+	}
+
+	applied := fx.ApplySpread(midRate, s.fxSpreadBps)
+	converted, err := fx.Convert(amount, from, to, applied)
+	if err != nil {
+		return nil, err
+	}
+	if converted <= 0 {
+		return nil, types.ErrConvertedAmountTooSmall
+	}
+
+	return &types.Conversion{
+		SourceAmount:        amount,
+		SourceCurrency:      from,
+		DestinationAmount:   converted,
+		DestinationCurrency: to,
+		RateID:              rate.ID,
+		Rate:                fx.FormatRate(applied),
+	}, nil
+}
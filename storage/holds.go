@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+// Authorize places a hold on amount of fromWallet balance in favor of toWallet and returns identifier of the hold.
+// Held amount isn't available for other operations until the hold is captured, voided or expired.
+// The hold is checked against transfer limits of fromWallet like the transfer made on capture.
+// The request with already used idempotency key is applied only once and returns the original hold identifier.
+func (s *storage) Authorize(ctx context.Context, fromWallet, toWallet types.WalletID, amount types.Money, idemKey *types.IdempotencyKey) (types.HoldID, error) {
+	var holdID types.HoldID
+	err := s.runTx(ctx, "authorize", func(tx *sqlx.Tx) error {
+		replayed, result, err := s.acquireIdempotencyKey(ctx, tx, idemKey)
+		if err != nil {
+			return err
+		}
+		if replayed {
+			holdID = types.HoldID(result)
+			return nil
+		}
+
+		// wallets are locked like on transfer, so status of toWallet can't change until the hold is placed
+		from, _, err := lockTransferWallets(ctx, tx, fromWallet, toWallet)
+		if err != nil {
+			return err
+		}
+
+		// limits are checked again on capture, when the transfer is made
+		if err := checkTransferLimits(ctx, tx, fromWallet, from.currency, amount); err != nil {
+			return err
+		}
+
+		held, err := heldAmount(ctx, tx, fromWallet)
+		if err != nil {
+			return err
+		}
+
+		if err := checkDebit(from.balance, held, from.overdraftLimit, amount); err != nil {
+			return err
+		}
+
+		id, err := newUUID()
+		if err != nil {
+			return errors.Wrap(err, "generate hold id")
+		}
+
+		if _, err := tx.ExecContext(ctx, queryInsertHold, id, fromWallet, toWallet, amount, s.holdTTL.Seconds()); err != nil {
+			return errors.Wrap(err, "create hold")
+		}
+
+		holdID = types.HoldID(id)
+		return saveIdempotencyResult(ctx, tx, idemKey, id)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return holdID, nil
+}
+
+// Capture transfers held amount fully (zero amount) or partially to the wallet specified on authorization,
+// the rest of held amount is released. It returns identifier of the transfer.
+// Held amount is in currency of the held wallet, it's converted by the exchange rate effective at capture.
+func (s *storage) Capture(ctx context.Context, holdID types.HoldID, amount types.Money) (types.TransferID, error) {
+	var transferID types.TransferID
+	err := s.runTx(ctx, "capture", func(tx *sqlx.Tx) error {
+		hold, err := lockActiveHold(ctx, tx, holdID)
+		if err != nil {
+			return err
+		}
+
+		// amount isn't changed, so the retried transaction captures the same amount
+		captured := amount
+		if captured == 0 {
+			captured = hold.Amount
+		}
+		if captured > hold.Amount {
+			return types.ErrInvalidCaptureAmount
+		}
+
+		// hold is completed before the transfer, so its amount becomes available for the transfer
+		if _, err := tx.ExecContext(ctx, queryCompleteHold, types.HoldStatusCaptured, holdID); err != nil {
+			return errors.Wrap(err, "complete hold")
+		}
+
+		if transferID, err = s.transfer(ctx, tx, hold.WalletID, hold.ToWalletID, captured); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, querySetHoldTransfer, transferID, captured, holdID)
+		return errors.Wrap(err, "save hold transfer")
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return transferID, nil
+}
+
+// Void releases held amount without moving money
+func (s *storage) Void(ctx context.Context, holdID types.HoldID) error {
+	return s.runTx(ctx, "void", func(tx *sqlx.Tx) error {
+		if _, err := lockActiveHold(ctx, tx, holdID); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, queryCompleteHold, types.HoldStatusVoided, holdID); err != nil {
+			return errors.Wrap(err, "complete hold")
+		}
+
+		return nil
+	})
+}
+
+// lockActiveHold locks the hold which is neither completed nor expired
+func lockActiveHold(ctx context.Context, tx *sqlx.Tx, holdID types.HoldID) (types.Hold, error) {
+	var hold types.Hold
+	if err := tx.GetContext(ctx, &hold, queryLockHold, holdID); err != nil {
+		if err == sql.ErrNoRows {
+			return hold, types.ErrHoldNotFound
+		}
+		return hold, errors.Wrap(err, "lock hold")
+	}
+
+	if hold.Status != types.HoldStatusActive {
+		return hold, types.ErrHoldNotActive
+	}
+
+	if hold.Expired {
+		return hold, types.ErrHoldExpired
+	}
+
+	return hold, nil
+}
+
+// heldAmount returns amount of wallet balance held by active holds, wallet should be locked by the transaction
+func heldAmount(ctx context.Context, tx *sqlx.Tx, wallet types.WalletID) (types.Money, error) {
+	var held types.Money
+	err := tx.QueryRowContext(ctx, querySelectHeldAmount, wallet).Scan(&held)
+	return held, errors.Wrap(err, "select held amount")
+}
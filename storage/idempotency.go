@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+// acquireIdempotencyKey binds idempotency key to the transaction, so the key is persisted only together with operations.
+// It returns true and the saved result if the key was already used by the same request earlier
+// and the request must not be applied again.
+// Concurrent requests with the same key wait on the unique index until the first transaction is completed.
+func (s *storage) acquireIdempotencyKey(ctx context.Context, tx *sqlx.Tx, idemKey *types.IdempotencyKey) (bool, string, error) {
+	if idemKey == nil {
+		return false, "", nil
+	}
+
+	if _, err := tx.ExecContext(ctx, queryDeleteExpiredIdempotencyKey, idemKey.Key, s.idempotencyRetention.Seconds()); err != nil {
+		return false, "", errors.Wrap(err, "delete expired idempotency key")
+	}
+
+	res, err := tx.ExecContext(ctx, queryInsertIdempotencyKey, idemKey.Key, idemKey.RequestHash)
+	if err != nil {
+		return false, "", errors.Wrap(err, "insert idempotency key")
+	}
+
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return false, "", errors.Wrap(err, "insert idempotency key result")
+	}
+
+	if inserted == 1 {
+		return false, "", nil
+	}
+
+	var requestHash, result string
+	if err := tx.QueryRowContext(ctx, querySelectIdempotencyKey, idemKey.Key).Scan(&requestHash, &result); err != nil {
+		return false, "", errors.Wrap(err, "select idempotency key")
+	}
+
+	if requestHash != idemKey.RequestHash {
+		return false, "", types.ErrIdempotencyKeyReused
+	}
+
+	return true, result, nil
+}
+
+// saveIdempotencyResult saves the result of request, which is returned to the retried requests with the same key
+func saveIdempotencyResult(ctx context.Context, tx *sqlx.Tx, idemKey *types.IdempotencyKey, result string) error {
+	if idemKey == nil {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, queryUpdateIdempotencyKeyResult, result, idemKey.Key)
+	return errors.Wrap(err, "update idempotency key result")
+}
+
+// PurgeIdempotencyKeys deletes idempotency keys which are older than retention period
+func (s *storage) PurgeIdempotencyKeys(ctx context.Context) (int64, error) {
+	res, err := s.conn.ExecContext(ctx, queryDeleteExpiredIdempotencyKeys, s.idempotencyRetention.Seconds())
+	if err != nil {
+		return 0, errors.Wrap(err, "delete expired idempotency keys")
+	}
+
+	purged, err := res.RowsAffected()
+	return purged, errors.Wrap(err, "delete expired idempotency keys result")
+}
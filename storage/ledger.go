@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+// postEntry writes journal entry with its postings and applies postings to the balances of wallet accounts,
+// so wallet balance is changed only together with the ledger.
+// Postings must sum to zero in every currency, the same invariant is checked by the database on commit.
+func postEntry(ctx context.Context, tx *sqlx.Tx, entryType types.EntryType, postings ...types.Posting) (int64, error) {
+	if len(postings) < 2 {
+		return 0, types.ErrUnbalancedEntry
+	}
+
+	sums := make(map[string]types.Money)
+	for _, posting := range postings {
+		sum, err := sums[posting.Currency].Add(posting.Amount)
+		if err != nil {
+			return 0, err
+		}
+		sums[posting.Currency] = sum
+	}
+
+	for _, sum := range sums {
+		if sum != 0 {
+			return 0, types.ErrUnbalancedEntry
+		}
+	}
+
+	var entryID int64
+	if err := tx.QueryRowContext(ctx, queryInsertJournalEntry, entryType).Scan(&entryID); err != nil {
+		return 0, errors.Wrap(err, "create journal entry")
+	}
+
+	for _, posting := range postings {
+		if _, err := tx.ExecContext(ctx, queryInsertPosting, entryID, posting.AccountID, posting.Currency, posting.Amount); err != nil {
+			return 0, errors.Wrap(err, "create posting")
+		}
+
+		if posting.AccountID.IsSystem() {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, queryApplyPostingToWallet, posting.Amount, posting.AccountID); err != nil {
+			return 0, errors.Wrap(err, "update balance")
+		}
+	}
+
+	return entryID, nil
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+// SetTransferLimits saves limits of the wallet or default limits of the currency replacing the previous ones
+func (s *storage) SetTransferLimits(ctx context.Context, limits types.TransferLimits) error {
+	if limits.Currency != "" {
+		_, err := s.conn.ExecContext(ctx, queryUpsertCurrencyTransferLimits,
+			limits.Currency, limits.MaxAmount, limits.MaxDailyAmount, limits.MaxHourlyCount)
+		return errors.Wrap(err, "save currency transfer limits")
+	}
+
+	var exists bool
+	if err := s.conn.QueryRowContext(ctx, queryWalletExists, limits.WalletID).Scan(&exists); err != nil {
+		return errors.Wrap(err, "check wallet exists")
+	}
+	if !exists {
+		return &types.WalletNotFoundError{Field: "wallet_id"}
+	}
+
+	_, err := s.conn.ExecContext(ctx, queryUpsertWalletTransferLimits,
+		limits.WalletID, limits.MaxAmount, limits.MaxDailyAmount, limits.MaxHourlyCount)
+	return errors.Wrap(err, "save wallet transfer limits")
+}
+
+// TransferLimits fetches default limits of currencies followed by limits of wallets
+func (s *storage) TransferLimits(ctx context.Context) ([]types.TransferLimits, error) {
+	var limits []types.TransferLimits
+	if err := s.conn.SelectContext(ctx, &limits, querySelectTransferLimits); err != nil {
+		return nil, errors.Wrap(err, "select transfer limits")
+	}
+	return limits, nil
+}
+
+// checkTransferLimits returns types.LimitExceededError if outgoing transfer or withdrawal of amount violates limits of the wallet.
+// The wallet must be locked by the transaction, so concurrent transfers are counted one after another.
+func checkTransferLimits(ctx context.Context, tx *sqlx.Tx, wallet types.WalletID, currencyCode string, amount types.Money) error {
+	var limits types.TransferLimits
+	if err := tx.GetContext(ctx, &limits, querySelectEffectiveTransferLimits, wallet, currencyCode); err != nil {
+		return errors.Wrap(err, "select transfer limits")
+	}
+
+	if limits.MaxAmount != nil && amount > *limits.MaxAmount {
+		return &types.LimitExceededError{Limit: types.LimitMaxAmount, Remaining: int64(*limits.MaxAmount)}
+	}
+
+	if limits.MaxDailyAmount == nil && limits.MaxHourlyCount == nil {
+		return nil
+	}
+
+	var usage struct {
+		DailyAmount types.Money `db:"daily_amount"`
+		HourlyCount int64       `db:"hourly_count"`
+	}
+	if err := tx.GetContext(ctx, &usage, querySelectOutgoingTransfersUsage, wallet); err != nil {
+		return errors.Wrap(err, "select outgoing transfers usage")
+	}
+
+	if limits.MaxHourlyCount != nil && usage.HourlyCount >= *limits.MaxHourlyCount {
+		return &types.LimitExceededError{Limit: types.LimitMaxHourlyCount, Remaining: 0}
+	}
+
+	if limits.MaxDailyAmount != nil {
+		remaining, err := limits.MaxDailyAmount.Sub(usage.DailyAmount)
+		if err != nil {
+			return err
+		}
+		if amount > remaining {
+			if remaining < 0 {
+				remaining = 0
+			}
+			return &types.LimitExceededError{Limit: types.LimitMaxDailyAmount, Remaining: int64(remaining)}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+// newOperation describes operation to insert, empty optional fields are stored as NULL
+type newOperation struct {
+	WalletID             types.WalletID
+	Type                 types.OperationType
+	Amount               types.Money
+	Currency             string
+	TransferID           string
+	CounterpartyWalletID types.WalletID
+	EntryID              int64
+	ReversalOf           int64
+	Conversion           *types.Conversion
+}
+
+// insertOperation inserts operation and returns its public identifier
+func insertOperation(ctx context.Context, tx *sqlx.Tx, op newOperation) (types.OperationID, error) {
+	publicID, err := newUUID()
+	if err != nil {
+		return "", errors.Wrap(err, "generate operation id")
+	}
+
+	var (
+		sourceAmount, destinationAmount, rateID sql.NullInt64
+		sourceCurrency, destinationCurrency     sql.NullString
+		rate                                    sql.NullString
+	)
+	if c := op.Conversion; c != nil {
+		sourceAmount = sql.NullInt64{Int64: int64(c.SourceAmount), Valid: true}
+		sourceCurrency = sql.NullString{String: c.SourceCurrency, Valid: true}
+		destinationAmount = sql.NullInt64{Int64: int64(c.DestinationAmount), Valid: true}
+		destinationCurrency = sql.NullString{String: c.DestinationCurrency, Valid: true}
+		rate = sql.NullString{String: c.Rate, Valid: true}
+		rateID = sql.NullInt64{Int64: c.RateID, Valid: true}
+	}
+
+	_, err = tx.ExecContext(ctx, queryInsertOperation,
+		op.WalletID,
+		op.Type,
+		op.Amount,
+		op.Currency,
+		sql.NullString{String: op.TransferID, Valid: op.TransferID != ""},
+		sql.NullString{String: string(op.CounterpartyWalletID), Valid: op.CounterpartyWalletID != ""},
+		op.EntryID,
+		sql.NullInt64{Int64: op.ReversalOf, Valid: op.ReversalOf != 0},
+		sourceAmount,
+		sourceCurrency,
+		destinationAmount,
+		destinationCurrency,
+		rate,
+		rateID,
+		publicID,
+	)
+	if err != nil {
+		return "", errors.Wrapf(err, "create operation %s", op.Type)
+	}
+
+	return types.OperationID(publicID), nil
+}
+
+// Operation returns operation by its public identifier with the balance of the wallet after it
+func (s *storage) Operation(ctx context.Context, operationID types.OperationID) (types.DBOperation, error) {
+	var op types.DBOperation
+	if err := s.conn.GetContext(ctx, &op, querySelectOperation, operationID); err != nil {
+		if err == sql.ErrNoRows {
+			return op, types.ErrOperationNotFound
+		}
+		return op, errors.Wrap(err, "select operation")
+	}
+
+	return op, nil
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+// SetOverdraftLimit sets the amount by which wallet balance can go below zero,
+// the limit can't be less than the credit already used by the wallet
+func (s *storage) SetOverdraftLimit(ctx context.Context, wallet types.WalletID, limit types.Money) error {
+	return s.runTx(ctx, "set_overdraft_limit", func(tx *sqlx.Tx) error {
+		var (
+			balance types.Money
+			status  types.WalletStatus
+		)
+		if err := tx.QueryRowContext(ctx, queryLockWalletStatus, wallet).Scan(&balance, &status); err != nil {
+			if err == sql.ErrNoRows {
+				return &types.WalletNotFoundError{Field: "wallet"}
+			}
+			return errors.Wrap(err, "lock wallet")
+		}
+
+		if status == types.WalletStatusClosed {
+			return types.ErrWalletClosed
+		}
+
+		held, err := heldAmount(ctx, tx, wallet)
+		if err != nil {
+			return err
+		}
+
+		if err := checkDebit(balance, held, limit, 0); err != nil {
+			if errors.Cause(err) == types.ErrUnavailableBalance {
+				return types.ErrOverdraftLimitTooLow
+			}
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, queryUpdateOverdraftLimit, limit, wallet); err != nil {
+			return errors.Wrap(err, "update overdraft limit")
+		}
+
+		return nil
+	})
+}
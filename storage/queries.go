@@ -8,33 +8,419 @@ func removeExtraWhitespaces(query string) string {
 	return clearQueryWhitespacesRegex.ReplaceAllString(query, " ")
 }
 
+// operationSignedAmount is the change of wallet balance made by the operation
+const operationSignedAmount = `CASE operation_type WHEN 'withdraw' THEN -amount ELSE amount END`
+
+// operationColumns are columns of types.DBOperation selected from operations,
+// reversed operation is referred by its public identifier
+const operationColumns = `id, public_id, wallet_id, operation_type, amount, currency,
+	COALESCE(transfer_id::text, '') as transfer_id,
+	COALESCE(counterparty_wallet_id, '') as counterparty_wallet_id,
+	COALESCE((SELECT r.public_id::text FROM operations r WHERE r.id = operations.reversal_of), '') as reversal_of,
+	COALESCE(source_amount, 0) as source_amount,
+	COALESCE(source_currency, '') as source_currency,
+	COALESCE(destination_amount, 0) as destination_amount,
+	COALESCE(destination_currency, '') as destination_currency,
+	COALESCE(trim_scale(fx_rate)::text, '') as fx_rate,
+	used_credit, overdraft_limit, balance_after, created_at`
+
+// heldAmountSubquery sums amounts of active not expired holds of the wallet with id $1
+const heldAmountSubquery = `SELECT COALESCE(SUM(amount), 0) FROM holds
+	WHERE wallet_id = $1 AND status = 'active' AND expires_at > NOW()`
+
 var (
 	queryInsertWallet = removeExtraWhitespaces(`
-		INSERT INTO wallet(id, balance, created_at)
-		VALUES ($1, 0, DEFAULT)`,
+		INSERT INTO wallet(id, currency, balance, created_at)
+		VALUES ($1, $2, 0, DEFAULT)`,
+	)
+
+	querySelectWallet = removeExtraWhitespaces(`
+		SELECT w.id, w.currency, w.status, w.balance, w.overdraft_limit, w.created_at,
+			COUNT(o.id) as operations_count,
+			MAX(o.created_at) as last_operation_at,
+			(` + heldAmountSubquery + `) as held_amount
+		FROM wallet w
+		LEFT JOIN operations o ON o.wallet_id = w.id
+		WHERE w.id = $1
+		GROUP BY w.id`,
+	)
+
+	querySelectHeldAmount = removeExtraWhitespaces(heldAmountSubquery)
+
+	queryInsertHold = removeExtraWhitespaces(`
+		INSERT INTO holds(id, wallet_id, to_wallet_id, amount, status, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, 'active', NOW() + make_interval(secs => $5), DEFAULT)`,
+	)
+
+	queryLockHold = removeExtraWhitespaces(`
+		SELECT id, wallet_id, to_wallet_id, amount, status, expires_at <= NOW() as expired
+		FROM holds
+		WHERE id = $1
+		FOR UPDATE`,
+	)
+
+	queryCompleteHold = removeExtraWhitespaces(`
+		UPDATE holds SET status = $1, completed_at = NOW() WHERE id = $2`,
+	)
+
+	querySetHoldTransfer = removeExtraWhitespaces(`
+		UPDATE holds SET transfer_id = $1, captured_amount = $2 WHERE id = $3`,
+	)
+
+	queryWalletExists = removeExtraWhitespaces(`
+		SELECT EXISTS(SELECT 1 FROM wallet WHERE id = $1)`,
+	)
+
+	querySelectWalletStatus = removeExtraWhitespaces(`
+		SELECT status FROM wallet WHERE id = $1`,
+	)
+
+	queryLockWalletStatus = removeExtraWhitespaces(`
+		SELECT balance, status FROM wallet WHERE id = $1 FOR UPDATE`,
+	)
+
+	queryUpdateOverdraftLimit = removeExtraWhitespaces(`
+		UPDATE wallet SET overdraft_limit = $1 WHERE id = $2`,
+	)
+
+	queryUpdateWalletStatus = removeExtraWhitespaces(`
+		UPDATE wallet SET status = $1 WHERE id = $2`,
+	)
+
+	queryInsertWalletStatusChange = removeExtraWhitespaces(`
+		INSERT INTO wallet_status_history(id, wallet_id, from_status, to_status, reason, created_at)
+		VALUES (DEFAULT, $1, $2, $3, $4, DEFAULT)`,
+	)
+
+	querySelectWalletStatusHistory = removeExtraWhitespaces(`
+		SELECT wallet_id, from_status, to_status, reason, created_at
+		FROM wallet_status_history
+		WHERE wallet_id = $1
+		ORDER BY id`,
 	)
 
 	queryLockWalletForCreate = removeExtraWhitespaces(`
-		SELECT balance FROM wallet WHERE id = $1 FOR UPDATE`,
+		SELECT balance, currency, status, overdraft_limit FROM wallet WHERE id = $1 FOR UPDATE`,
 	)
 
+	// wallets are locked in order of identifiers, so concurrent transfers A->B and B->A don't deadlock
 	queryLockWalletsForTransfer = removeExtraWhitespaces(`
-		SELECT id, balance FROM wallet WHERE id IN($1,$2) FOR UPDATE`,
+		SELECT id, balance, currency, status, overdraft_limit FROM wallet WHERE id IN($1,$2) ORDER BY id FOR UPDATE`,
+	)
+
+	queryLockWallets = removeExtraWhitespaces(`
+		SELECT id, balance, currency, status, overdraft_limit FROM wallet WHERE id = ANY($1) ORDER BY id FOR UPDATE`,
 	)
 
+	queryLockOperation = removeExtraWhitespaces(`
+		SELECT id, wallet_id, operation_type, amount, currency,
+			COALESCE(transfer_id::text, '') as transfer_id,
+			COALESCE(counterparty_wallet_id, '') as counterparty_wallet_id
+		FROM operations
+		WHERE public_id = $1
+		FOR UPDATE`,
+	)
+
+	queryLockTransferOperations = removeExtraWhitespaces(`
+		SELECT id, wallet_id, operation_type, amount, currency,
+			COALESCE(transfer_id::text, '') as transfer_id,
+			COALESCE(counterparty_wallet_id, '') as counterparty_wallet_id
+		FROM operations
+		WHERE transfer_id = $1
+		ORDER BY id
+		FOR UPDATE`,
+	)
+
+	// amounts of reversals are signed changes of balance
+	querySelectReversedAmounts = removeExtraWhitespaces(`
+		SELECT reversal_of, SUM(ABS(amount)) FROM operations
+		WHERE reversal_of = ANY($1)
+		GROUP BY reversal_of`,
+	)
+
+	querySelectEntryOperationIDs = removeExtraWhitespaces(`
+		SELECT public_id FROM operations WHERE entry_id = $1 ORDER BY id`,
+	)
+
+	// balance and credit usage are taken from the wallet, postings of the operation are already applied to its balance,
+	// credit usage is negative available balance like in types.Wallet.UsedCredit.
+	// The wallet is locked by the transaction, so creation time is taken after the lock to order operations
+	// of the wallet the same way as their balances.
 	queryInsertOperation = removeExtraWhitespaces(`
-		INSERT INTO operations(id, wallet_id, operation_type, amount, created_at)
-		VALUES (DEFAULT, $1, $2, $3, DEFAULT)`,
+		INSERT INTO operations(id, wallet_id, operation_type, amount, currency, transfer_id, counterparty_wallet_id, entry_id, reversal_of,
+			source_amount, source_currency, destination_amount, destination_currency, fx_rate, fx_rate_id,
+			balance_after, used_credit, overdraft_limit, public_id, created_at)
+		VALUES (DEFAULT, $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
+			(SELECT balance FROM wallet WHERE id = $1),
+			(SELECT GREATEST((` + heldAmountSubquery + `) - balance, 0) FROM wallet WHERE id = $1),
+			(SELECT overdraft_limit FROM wallet WHERE id = $1),
+			$15, clock_timestamp())`,
+	)
+
+	queryInsertFXRate = removeExtraWhitespaces(`
+		INSERT INTO fx_rates(id, base_currency, quote_currency, rate, effective_from, created_at)
+		VALUES (DEFAULT, $1, $2, $3, $4, DEFAULT)
+		ON CONFLICT (base_currency, quote_currency, effective_from) DO NOTHING`,
+	)
+
+	querySelectSameFXRate = removeExtraWhitespaces(`
+		SELECT rate = $4
+		FROM fx_rates
+		WHERE base_currency = $1 AND quote_currency = $2 AND effective_from = $3`,
+	)
+
+	querySelectEffectiveFXRate = removeExtraWhitespaces(`
+		SELECT id, base_currency, quote_currency, rate::text as rate, effective_from
+		FROM fx_rates
+		WHERE base_currency = $1 AND quote_currency = $2 AND effective_from <= NOW()
+		ORDER BY effective_from DESC
+		LIMIT 1`,
+	)
+
+	queryUpsertWalletTransferLimits = removeExtraWhitespaces(`
+		INSERT INTO transfer_limits(wallet_id, max_amount, max_daily_amount, max_hourly_count)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (wallet_id) WHERE wallet_id IS NOT NULL
+		DO UPDATE SET max_amount = EXCLUDED.max_amount, max_daily_amount = EXCLUDED.max_daily_amount,
+			max_hourly_count = EXCLUDED.max_hourly_count, updated_at = NOW()`,
+	)
+
+	queryUpsertCurrencyTransferLimits = removeExtraWhitespaces(`
+		INSERT INTO transfer_limits(currency, max_amount, max_daily_amount, max_hourly_count)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (currency) WHERE currency IS NOT NULL
+		DO UPDATE SET max_amount = EXCLUDED.max_amount, max_daily_amount = EXCLUDED.max_daily_amount,
+			max_hourly_count = EXCLUDED.max_hourly_count, updated_at = NOW()`,
+	)
+
+	querySelectTransferLimits = removeExtraWhitespaces(`
+		SELECT COALESCE(wallet_id, '') as wallet_id, COALESCE(currency, '') as currency,
+			max_amount, max_daily_amount, max_hourly_count
+		FROM transfer_limits
+		ORDER BY currency NULLS LAST, wallet_id`,
+	)
+
+	// limits of the wallet $1 replace default limits of its currency $2 if they are set
+	querySelectEffectiveTransferLimits = removeExtraWhitespaces(`
+		SELECT CASE WHEN w.id IS NULL THEN c.max_amount ELSE w.max_amount END as max_amount,
+			CASE WHEN w.id IS NULL THEN c.max_daily_amount ELSE w.max_daily_amount END as max_daily_amount,
+			CASE WHEN w.id IS NULL THEN c.max_hourly_count ELSE w.max_hourly_count END as max_hourly_count
+		FROM (SELECT 1) s
+		LEFT JOIN transfer_limits w ON w.wallet_id = $1
+		LEFT JOIN transfer_limits c ON c.currency = $2`,
+	)
+
+	// outgoing legs of transfers and withdrawals are both withdraw operations
+	querySelectOutgoingTransfersUsage = removeExtraWhitespaces(`
+		SELECT COALESCE(SUM(amount), 0) as daily_amount,
+			COUNT(*) FILTER (WHERE created_at > NOW() - INTERVAL '1 hour') as hourly_count
+		FROM operations
+		WHERE wallet_id = $1 AND operation_type = 'withdraw'
+			AND created_at > NOW() - INTERVAL '1 day'`,
+	)
+
+	queryInsertTransferBatch = removeExtraWhitespaces(`
+		INSERT INTO transfer_batches(id, from_wallet_id, currency, total_amount, created_at)
+		VALUES ($1, $2, $3, $4, DEFAULT)
+		RETURNING created_at`,
 	)
 
-	queryUpdateWallet = removeExtraWhitespaces(`
-		UPDATE wallet SET balance = $1 WHERE id = $2`,
+	queryInsertTransferBatchLeg = removeExtraWhitespaces(`
+		INSERT INTO transfer_batch_legs(batch_id, leg_index, to_wallet_id, amount, transfer_id)
+		VALUES ($1, $2, $3, $4, $5)`,
+	)
+
+	querySelectTransferBatch = removeExtraWhitespaces(`
+		SELECT id, from_wallet_id, currency, total_amount, created_at FROM transfer_batches WHERE id = $1`,
+	)
+
+	querySelectTransferBatchLegs = removeExtraWhitespaces(`
+		SELECT to_wallet_id, amount, transfer_id FROM transfer_batch_legs WHERE batch_id = $1 ORDER BY leg_index`,
+	)
+
+	queryInsertSchedule = removeExtraWhitespaces(`
+		INSERT INTO scheduled_transfers(id, from_wallet_id, to_wallet_id, amount, recurrence, start_at, timezone, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $6)
+		RETURNING id, from_wallet_id, to_wallet_id, amount, recurrence, start_at, timezone, next_run_at, status, created_at`,
+	)
+
+	querySelectWalletSchedules = removeExtraWhitespaces(`
+		SELECT id, from_wallet_id, to_wallet_id, amount, recurrence, start_at, timezone, next_run_at, status, created_at
+		FROM scheduled_transfers
+		WHERE from_wallet_id = $1
+		ORDER BY created_at, id`,
+	)
+
+	querySelectDueSchedules = removeExtraWhitespaces(`
+		SELECT id, from_wallet_id, to_wallet_id, amount, recurrence, start_at, timezone, next_run_at, status, created_at
+		FROM scheduled_transfers
+		WHERE status = 'active' AND next_run_at <= $1
+		ORDER BY next_run_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED`,
+	)
+
+	// schedule which is being executed by another transaction is skipped
+	queryLockDueSchedule = removeExtraWhitespaces(`
+		SELECT id FROM scheduled_transfers
+		WHERE id = $1 AND status = 'active' AND next_run_at = $2
+		FOR UPDATE SKIP LOCKED`,
+	)
+
+	queryCancelSchedule = removeExtraWhitespaces(`
+		UPDATE scheduled_transfers SET status = 'cancelled', next_run_at = NULL
+		WHERE id = $1 AND status = 'active'`,
+	)
+
+	queryScheduleExists = removeExtraWhitespaces(`
+		SELECT EXISTS(SELECT 1 FROM scheduled_transfers WHERE id = $1)`,
+	)
+
+	// schedule is moved to the next run only if it's still waiting for the recorded one, NULL next run completes it
+	queryAdvanceSchedule = removeExtraWhitespaces(`
+		UPDATE scheduled_transfers
+		SET next_run_at = $2, status = CASE WHEN $2::timestamptz IS NULL THEN 'completed'::schedule_status ELSE status END
+		WHERE id = $1 AND status = 'active' AND next_run_at = $3`,
+	)
+
+	queryClaimScheduleRun = removeExtraWhitespaces(`
+		INSERT INTO scheduled_transfer_runs(schedule_id, run_at)
+		VALUES ($1, $2)
+		ON CONFLICT (schedule_id, run_at) DO NOTHING`,
+	)
+
+	queryUpdateScheduleRun = removeExtraWhitespaces(`
+		UPDATE scheduled_transfer_runs
+		SET transfer_id = NULLIF($3, '')::uuid, error = NULLIF($4, ''), executed_at = NOW()
+		WHERE schedule_id = $1 AND run_at = $2`,
+	)
+
+	querySavepointScheduleTransfer           = `SAVEPOINT schedule_transfer`
+	queryRollbackToSavepointScheduleTransfer = `ROLLBACK TO SAVEPOINT schedule_transfer`
+
+	querySelectScheduleRuns = removeExtraWhitespaces(`
+		SELECT schedule_id, run_at, COALESCE(transfer_id::text, '') as transfer_id, COALESCE(error, '') as error, executed_at
+		FROM scheduled_transfer_runs
+		WHERE schedule_id = $1
+		ORDER BY run_at`,
+	)
+
+	queryInsertJournalEntry = removeExtraWhitespaces(`
+		INSERT INTO journal_entries(id, entry_type, created_at)
+		VALUES (DEFAULT, $1, DEFAULT)
+		RETURNING id`,
+	)
+
+	queryInsertPosting = removeExtraWhitespaces(`
+		INSERT INTO postings(id, entry_id, account_id, currency, amount)
+		VALUES (DEFAULT, $1, $2, $3, $4)`,
+	)
+
+	queryApplyPostingToWallet = removeExtraWhitespaces(`
+		UPDATE wallet SET balance = balance + $1 WHERE id = $2`,
+	)
+
+	queryDeleteExpiredIdempotencyKey = removeExtraWhitespaces(`
+		DELETE FROM idempotency_keys
+		WHERE key = $1 AND created_at < NOW() - make_interval(secs => $2)`,
+	)
+
+	queryDeleteExpiredIdempotencyKeys = removeExtraWhitespaces(`
+		DELETE FROM idempotency_keys
+		WHERE created_at < NOW() - make_interval(secs => $1)`,
+	)
+
+	queryInsertIdempotencyKey = removeExtraWhitespaces(`
+		INSERT INTO idempotency_keys(key, request_hash, created_at)
+		VALUES ($1, $2, DEFAULT)
+		ON CONFLICT (key) DO NOTHING`,
+	)
+
+	querySelectIdempotencyKey = removeExtraWhitespaces(`
+		SELECT request_hash, result FROM idempotency_keys WHERE key = $1`,
+	)
+
+	queryUpdateIdempotencyKeyResult = removeExtraWhitespaces(`
+		UPDATE idempotency_keys SET result = $1 WHERE key = $2`,
 	)
 
 	querySelectOperations = removeExtraWhitespaces(`
-		SELECT wallet_id, operation_type, amount, TO_CHAR(created_at, 'YYYY-MM-DD') as created_at
+		SELECT ` + operationColumns + `
 		FROM operations
 		WHERE wallet_id = :wallet_id %s
-		ORDER BY created_at DESC`,
+		ORDER BY created_at DESC, id DESC
+		LIMIT :limit`,
+	)
+
+	querySelectOperation = removeExtraWhitespaces(`
+		SELECT ` + operationColumns + `
+		FROM operations
+		WHERE public_id = $1`,
+	)
+
+	// balances of the wallet before $2 and before $3 are balances after the last operations before them,
+	// NULL $2 means zero opening balance and NULL $3 means the current balance
+	querySelectRangeBalances = removeExtraWhitespaces(`
+		SELECT w.currency,
+			COALESCE((
+				SELECT balance_after FROM operations
+				WHERE wallet_id = w.id AND created_at < $2
+				ORDER BY created_at DESC, id DESC
+				LIMIT 1
+			), 0) as opening_balance,
+			COALESCE((
+				SELECT balance_after FROM operations
+				WHERE wallet_id = w.id AND ($3::timestamptz IS NULL OR created_at < $3::timestamptz)
+				ORDER BY created_at DESC, id DESC
+				LIMIT 1
+			), 0) as closing_balance
+		FROM wallet w
+		WHERE w.id = $1`,
+	)
+
+	// the last operation of the page and the one after it, if any
+	querySelectOperationsPageEnd = removeExtraWhitespaces(`
+		SELECT created_at, id
+		FROM operations
+		WHERE wallet_id = :wallet_id %s
+		ORDER BY created_at DESC, id DESC
+		OFFSET :page_end
+		LIMIT 2`,
+	)
+
+	querySelectBalanceDrifts = removeExtraWhitespaces(`
+		SELECT w.id as wallet_id, w.balance,
+			COALESCE(o.balance, 0) as operations_balance,
+			COALESCE(p.balance, 0) as ledger_balance
+		FROM wallet w
+		LEFT JOIN (
+			SELECT wallet_id, SUM(` + operationSignedAmount + `) as balance FROM operations GROUP BY wallet_id
+		) o ON o.wallet_id = w.id
+		LEFT JOIN (
+			SELECT account_id, SUM(amount) as balance FROM postings GROUP BY account_id
+		) p ON p.account_id = w.id
+		WHERE w.balance <> COALESCE(o.balance, 0) OR w.balance <> COALESCE(p.balance, 0)
+		ORDER BY w.id`,
+	)
+
+	querySelectOrphanTransfers = removeExtraWhitespaces(`
+		SELECT transfer_id::text as transfer_id, COUNT(*) as legs
+		FROM operations
+		WHERE transfer_id IS NOT NULL
+		GROUP BY transfer_id
+		HAVING COUNT(*) <> 2 OR COUNT(DISTINCT operation_type) <> 2
+		ORDER BY transfer_id`,
+	)
+
+	querySelectNegativeBalances = removeExtraWhitespaces(`
+		SELECT id as wallet_id, balance, overdraft_limit FROM wallet WHERE balance < -overdraft_limit ORDER BY id`,
+	)
+
+	querySelectUnbalancedEntries = removeExtraWhitespaces(`
+		SELECT entry_id, currency, SUM(amount) as sum
+		FROM postings
+		GROUP BY entry_id, currency
+		HAVING SUM(amount) <> 0
+		ORDER BY entry_id, currency`,
 	)
 )
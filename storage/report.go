@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+// Operations fetches a page of wallet operations from the newest to the oldest with the balance after each one
+// and returns them with the cursor of the next page and balances of the wallet at the boundaries of the date range.
+// The page is read in a repeatable read transaction, so the page, its cursor and balances are consistent.
+// The transaction is completed before rows are returned, so slow readers of the report don't hold database connections.
+func (s *storage) Operations(ctx context.Context, wallet types.WalletID, filter types.OperationsFilter) (types.OperationsPage, error) {
+	var page types.OperationsPage
+
+	where := ""
+	args := map[string]interface{}{
+		"wallet_id": wallet,
+		"limit":     filter.Limit,
+		"page_end":  filter.Limit - 1,
+	}
+
+	if filter.Type != "" {
+		where += " AND operation_type = :operation_type"
+		args["operation_type"] = filter.Type
+	}
+
+	// nil boundaries are NULL in the balances query
+	var from, to interface{}
+	if !filter.From.IsZero() {
+		from = filter.From
+		where += " AND created_at >= :from"
+		args["from"] = from
+	}
+
+	if !filter.To.IsZero() {
+		to = filter.To
+		where += " AND created_at < :to"
+		args["to"] = to
+	}
+
+	if filter.Cursor != nil {
+		where += " AND (created_at, id) < (:cursor_created_at, :cursor_id)"
+		args["cursor_created_at"] = filter.Cursor.CreatedAt
+		args["cursor_id"] = filter.Cursor.ID
+	}
+
+	tx, err := s.conn.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return page, errors.Wrap(err, "begin transaction")
+	}
+
+	err = tx.QueryRowContext(ctx, querySelectRangeBalances, wallet, from, to).Scan(&page.Currency, &page.OpeningBalance, &page.ClosingBalance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return page, completeTx(tx, &types.WalletNotFoundError{Field: "wallet"})
+		}
+		return page, completeTx(tx, errors.Wrap(err, "select range balances"))
+	}
+
+	if page.NextCursor, err = nextOperationsCursor(ctx, tx, where, args); err != nil {
+		return page, completeTx(tx, err)
+	}
+
+	query, params, err := sqlx.Named(removeExtraWhitespaces(fmt.Sprintf(querySelectOperations, where)), args)
+	if err != nil {
+		return page, completeTx(tx, errors.Wrap(err, "prepare named query"))
+	}
+
+	var ops []types.DBOperation
+	if err := tx.SelectContext(ctx, &ops, tx.Rebind(query), params...); err != nil {
+		return page, completeTx(tx, errors.Wrap(err, "select operations"))
+	}
+
+	if err := completeTx(tx, nil); err != nil {
+		return page, err
+	}
+
+	page.Rows = &operationRows{ops: ops}
+	return page, nil
+}
+
+// nextOperationsCursor returns cursor of the last operation of the page if there are operations after it
+func nextOperationsCursor(ctx context.Context, tx *sqlx.Tx, where string, args map[string]interface{}) (*types.OperationsCursor, error) {
+	query, params, err := sqlx.Named(removeExtraWhitespaces(fmt.Sprintf(querySelectOperationsPageEnd, where)), args)
+	if err != nil {
+		return nil, errors.Wrap(err, "prepare named query")
+	}
+
+	var pageEnd []struct {
+		CreatedAt time.Time `db:"created_at"`
+		ID        int64     `db:"id"`
+	}
+	if err := tx.SelectContext(ctx, &pageEnd, tx.Rebind(query), params...); err != nil {
+		return nil, errors.Wrap(err, "select page end")
+	}
+
+	if len(pageEnd) < 2 {
+		return nil, nil
+	}
+	return &types.OperationsCursor{CreatedAt: pageEnd[0].CreatedAt, ID: pageEnd[0].ID}, nil
+}
+
+// operationRows iterates over operations of the fetched page
+type operationRows struct {
+	ops []types.DBOperation
+	op  types.DBOperation
+}
+
+func (r *operationRows) Next() bool {
+	if len(r.ops) == 0 {
+		return false
+	}
+
+	r.op, r.ops = r.ops[0], r.ops[1:]
+	return true
+}
+
+func (r *operationRows) Operation() types.DBOperation {
+	return r.op
+}
+
+func (r *operationRows) Err() error {
+	return nil
+}
+
+func (r *operationRows) Close() error {
+	r.ops = nil
+	return nil
+}
@@ -0,0 +1,258 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"math/big"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/justteddy/wallet/types"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+type reversibleOperation struct {
+	ID                   int64               `db:"id"`
+	WalletID             types.WalletID      `db:"wallet_id"`
+	Type                 types.OperationType `db:"operation_type"`
+	Amount               types.Money         `db:"amount"`
+	Currency             string              `db:"currency"`
+	TransferID           string              `db:"transfer_id"`
+	CounterpartyWalletID types.WalletID      `db:"counterparty_wallet_id"`
+	// Reversed is the part of amount compensated by previous reversals
+	Reversed types.Money `db:"-"`
+}
+
+// remaining returns the part of operation amount which isn't reversed yet
+func (op reversibleOperation) remaining() types.Money {
+	return op.Amount - op.Reversed
+}
+
+// balanceChange is the change of wallet balance which compensates the operation
+func (op reversibleOperation) balanceChange(amount types.Money) types.Money {
+	if op.Type == types.OperationTypeDeposit {
+		return -amount
+	}
+	return amount
+}
+
+// reversedAmount returns the part of operation amount which is reversed when amount of the original operation is reversed.
+// Legs of cross-currency transfer have amounts in different currencies, so they are reversed proportionally,
+// reversal of the rest of the original operation reverses the rest of every leg, so rounding doesn't leave remainders.
+func (op reversibleOperation) reversedAmount(original reversibleOperation, amount types.Money) types.Money {
+	if amount == original.remaining() {
+		return op.remaining()
+	}
+
+	// amount doesn't exceed original amount, so the result fits into the leg amount
+	reversed := new(big.Int).Mul(big.NewInt(int64(op.Amount)), big.NewInt(int64(amount)))
+	reversed.Quo(reversed, big.NewInt(int64(original.Amount)))
+	if types.Money(reversed.Int64()) > op.remaining() {
+		return op.remaining()
+	}
+	return types.Money(reversed.Int64())
+}
+
+// externalAccount returns system account which took part in the operation, transfer legs don't have such one
+func (op reversibleOperation) externalAccount() types.AccountID {
+	if op.Type == types.OperationTypeDeposit {
+		return types.AccountExternalDeposits
+	}
+	return types.AccountExternalWithdrawals
+}
+
+// Reverse writes reversal operations which compensate the rest of the operation (zero amount) or its part
+// and returns their identifiers. Reversal of any transfer leg compensates both legs of the transfer,
+// legs of cross-currency transfer are compensated at the rate of the transfer.
+// Operation can be reversed by several partial reversals until its whole amount is reversed,
+// the request with already used idempotency key is applied only once and returns the original identifiers.
+func (s *storage) Reverse(ctx context.Context, operationID types.OperationID, amount types.Money, idemKey *types.IdempotencyKey) ([]types.OperationID, error) {
+	var reversalIDs []types.OperationID
+	err := s.runTx(ctx, "reverse", func(tx *sqlx.Tx) error {
+		replayed, result, err := s.acquireIdempotencyKey(ctx, tx, idemKey)
+		if err != nil {
+			return err
+		}
+		if replayed {
+			// reversal operations of the request are postings of the same journal entry
+			return tx.SelectContext(ctx, &reversalIDs, querySelectEntryOperationIDs, result)
+		}
+
+		var original reversibleOperation
+		if err := tx.GetContext(ctx, &original, queryLockOperation, operationID); err != nil {
+			if err == sql.ErrNoRows {
+				return types.ErrOperationNotFound
+			}
+			return errors.Wrap(err, "lock operation")
+		}
+
+		if original.Type == types.OperationTypeReversal {
+			return types.ErrOperationNotReversible
+		}
+
+		legs := []reversibleOperation{original}
+		if original.TransferID != "" {
+			legs = nil
+			if err := tx.SelectContext(ctx, &legs, queryLockTransferOperations, original.TransferID); err != nil {
+				return errors.Wrap(err, "lock transfer operations")
+			}
+		}
+
+		legIDs := make([]int64, 0, len(legs))
+		wallets := make([]string, 0, len(legs))
+		for _, leg := range legs {
+			legIDs = append(legIDs, leg.ID)
+			wallets = append(wallets, string(leg.WalletID))
+		}
+
+		// legs are locked, so concurrent reversals of the operation see amounts reversed by each other
+		reversed, err := reversedAmounts(ctx, tx, legIDs)
+		if err != nil {
+			return err
+		}
+		for i := range legs {
+			legs[i].Reversed = reversed[legs[i].ID]
+		}
+		original.Reversed = reversed[original.ID]
+
+		if original.remaining() == 0 {
+			return types.ErrOperationAlreadyReversed
+		}
+
+		// amount isn't changed, so the retried transaction reverses the same amount
+		reverseAmount := amount
+		if reverseAmount == 0 {
+			reverseAmount = original.remaining()
+		}
+		if reverseAmount > original.remaining() {
+			return types.ErrInvalidReversalAmount
+		}
+
+		locked, err := lockWallets(ctx, tx, wallets)
+		if err != nil {
+			return err
+		}
+
+		for _, leg := range legs {
+			if err := locked[leg.WalletID].Status.CheckActive("wallet"); err != nil {
+				return err
+			}
+		}
+
+		crossCurrency := len(legs) == 2 && legs[0].Currency != legs[1].Currency
+
+		postings := make([]types.Posting, 0, len(legs)*2)
+		changes := make([]types.Money, 0, len(legs))
+		for _, leg := range legs {
+			change := leg.balanceChange(leg.reversedAmount(original, reverseAmount))
+			if change == 0 {
+				return types.ErrConvertedAmountTooSmall
+			}
+			changes = append(changes, change)
+
+			if change < 0 {
+				held, err := heldAmount(ctx, tx, leg.WalletID)
+				if err != nil {
+					return err
+				}
+				wallet := locked[leg.WalletID]
+				if err := checkDebit(wallet.Balance, held, wallet.OverdraftLimit, -change); err != nil {
+					return err
+				}
+			} else if _, err := locked[leg.WalletID].Balance.Add(change); err != nil {
+				return err
+			}
+
+			postings = append(postings, types.Posting{AccountID: types.WalletAccount(leg.WalletID), Currency: leg.Currency, Amount: change})
+			switch {
+			case leg.TransferID == "":
+				postings = append(postings, types.Posting{AccountID: leg.externalAccount(), Currency: leg.Currency, Amount: -change})
+			case crossCurrency:
+				postings = append(postings, types.Posting{AccountID: types.AccountFX, Currency: leg.Currency, Amount: -change})
+			}
+		}
+
+		entryID, err := postEntry(ctx, tx, types.EntryTypeReversal, postings...)
+		if err != nil {
+			return err
+		}
+
+		reversalIDs = make([]types.OperationID, 0, len(legs))
+		for i, leg := range legs {
+			id, err := insertOperation(ctx, tx, newOperation{
+				WalletID:             leg.WalletID,
+				Type:                 types.OperationTypeReversal,
+				Amount:               changes[i],
+				Currency:             leg.Currency,
+				CounterpartyWalletID: leg.CounterpartyWalletID,
+				EntryID:              entryID,
+				ReversalOf:           leg.ID,
+			})
+			if err != nil {
+				return err
+			}
+			reversalIDs = append(reversalIDs, id)
+		}
+
+		return saveIdempotencyResult(ctx, tx, idemKey, strconv.FormatInt(entryID, 10))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reversalIDs, nil
+}
+
+// reversedAmounts returns amounts of operations compensated by their reversals
+func reversedAmounts(ctx context.Context, tx *sqlx.Tx, operationIDs []int64) (map[int64]types.Money, error) {
+	rows, err := tx.QueryContext(ctx, querySelectReversedAmounts, pq.Array(operationIDs))
+	if err != nil {
+		return nil, errors.Wrap(err, "select reversed amounts")
+	}
+	defer rows.Close()
+
+	reversed := make(map[int64]types.Money, len(operationIDs))
+	for rows.Next() {
+		var (
+			id     int64
+			amount types.Money
+		)
+		if err := rows.Scan(&id, &amount); err != nil {
+			return nil, errors.Wrap(err, "scan rows")
+		}
+		reversed[id] = amount
+	}
+
+	return reversed, errors.Wrap(rows.Err(), "iterate rows")
+}
+
+type lockedWallet struct {
+	Balance        types.Money
+	Currency       string
+	Status         types.WalletStatus
+	OverdraftLimit types.Money
+}
+
+// lockWallets locks wallets in order of their identifiers and returns their balances, currencies, statuses and overdraft limits
+func lockWallets(ctx context.Context, tx *sqlx.Tx, wallets []string) (map[types.WalletID]lockedWallet, error) {
+	rows, err := tx.QueryContext(ctx, queryLockWallets, pq.Array(wallets))
+	if err != nil {
+		return nil, errors.Wrap(err, "lock wallets")
+	}
+	defer rows.Close()
+
+	locked := make(map[types.WalletID]lockedWallet, len(wallets))
+	for rows.Next() {
+		var (
+			id     types.WalletID
+			wallet lockedWallet
+		)
+		if err := rows.Scan(&id, &wallet.Balance, &wallet.Currency, &wallet.Status, &wallet.OverdraftLimit); err != nil {
+			return nil, errors.Wrap(err, "scan rows")
+		}
+		locked[id] = wallet
+	}
+
+	return locked, errors.Wrap(rows.Err(), "iterate rows")
+}
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+// CreateSchedule saves active schedule of transfers between active wallets, the first run is at start of the schedule
+func (s *storage) CreateSchedule(ctx context.Context, schedule types.Schedule) (types.Schedule, error) {
+	for _, wallet := range []struct {
+		id    types.WalletID
+		field string
+	}{
+		{id: schedule.FromWallet, field: "from_wallet"},
+		{id: schedule.ToWallet, field: "to_wallet"},
+	} {
+		var status types.WalletStatus
+		if err := s.conn.QueryRowContext(ctx, querySelectWalletStatus, wallet.id).Scan(&status); err != nil {
+			if err == sql.ErrNoRows {
+				return types.Schedule{}, &types.WalletNotFoundError{Field: wallet.field}
+			}
+			return types.Schedule{}, errors.Wrap(err, "select wallet status")
+		}
+		if err := status.CheckActive(wallet.field); err != nil {
+			return types.Schedule{}, err
+		}
+	}
+
+	scheduleID, err := newUUID()
+	if err != nil {
+		return types.Schedule{}, errors.Wrap(err, "generate schedule id")
+	}
+
+	var created types.Schedule
+	if err := s.conn.GetContext(ctx, &created, queryInsertSchedule, scheduleID, schedule.FromWallet, schedule.ToWallet,
+		schedule.Amount, schedule.Recurrence, schedule.StartAt, schedule.Timezone); err != nil {
+		return types.Schedule{}, errors.Wrap(err, "create schedule")
+	}
+
+	return created, nil
+}
+
+// WalletSchedules fetches schedules of transfers from the wallet in order of creation
+func (s *storage) WalletSchedules(ctx context.Context, wallet types.WalletID) ([]types.Schedule, error) {
+	var schedules []types.Schedule
+	if err := s.conn.SelectContext(ctx, &schedules, querySelectWalletSchedules, wallet); err != nil {
+		return nil, errors.Wrap(err, "select schedules")
+	}
+
+	if len(schedules) == 0 {
+		var exists bool
+		if err := s.conn.QueryRowContext(ctx, queryWalletExists, wallet).Scan(&exists); err != nil {
+			return nil, errors.Wrap(err, "check wallet exists")
+		}
+		if !exists {
+			return nil, &types.WalletNotFoundError{Field: "wallet"}
+		}
+	}
+
+	return schedules, nil
+}
+
+// CancelSchedule stops active schedule, the run which is already being executed isn't stopped
+func (s *storage) CancelSchedule(ctx context.Context, scheduleID types.ScheduleID) error {
+	res, err := s.conn.ExecContext(ctx, queryCancelSchedule, scheduleID)
+	if err != nil {
+		return errors.Wrap(err, "cancel schedule")
+	}
+
+	cancelled, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "cancel schedule result")
+	}
+	if cancelled == 1 {
+		return nil
+	}
+
+	var exists bool
+	if err := s.conn.QueryRowContext(ctx, queryScheduleExists, scheduleID).Scan(&exists); err != nil {
+		return errors.Wrap(err, "check schedule exists")
+	}
+	if !exists {
+		return types.ErrScheduleNotFound
+	}
+	return types.ErrScheduleNotActive
+}
+
+// ScheduleRuns fetches executions of the schedule from the oldest to the newest one
+func (s *storage) ScheduleRuns(ctx context.Context, scheduleID types.ScheduleID) ([]types.ScheduleRun, error) {
+	var runs []types.ScheduleRun
+	if err := s.conn.SelectContext(ctx, &runs, querySelectScheduleRuns, scheduleID); err != nil {
+		return nil, errors.Wrap(err, "select schedule runs")
+	}
+
+	if len(runs) == 0 {
+		var exists bool
+		if err := s.conn.QueryRowContext(ctx, queryScheduleExists, scheduleID).Scan(&exists); err != nil {
+			return nil, errors.Wrap(err, "check schedule exists")
+		}
+		if !exists {
+			return nil, types.ErrScheduleNotFound
+		}
+	}
+
+	return runs, nil
+}
+
+// DueSchedules fetches up to limit active schedules which next run is not after now, the most overdue go first.
+// Schedules locked by runs being executed are skipped, the rows aren't kept locked after the fetch.
+func (s *storage) DueSchedules(ctx context.Context, now time.Time, limit int) ([]types.Schedule, error) {
+	var schedules []types.Schedule
+	if err := s.conn.SelectContext(ctx, &schedules, querySelectDueSchedules, now, limit); err != nil {
+		return nil, errors.Wrap(err, "select due schedules")
+	}
+	return schedules, nil
+}
+
+// RunSchedule executes the run of the schedule planned at its next run and moves the schedule to next run
+// in one transaction, nil next run completes the schedule. The schedule is locked unless another execution holds it
+// and the run is claimed by its unique row before the transfer, so every run is transferred only once.
+// Transfer rejected because of wallets state, limits or exchange rates is rolled back and the run is recorded with the error.
+// types.ErrScheduleRunClaimed is returned if the run is being executed or is already executed by another process.
+func (s *storage) RunSchedule(ctx context.Context, schedule types.Schedule, next *time.Time) (types.ScheduleRun, error) {
+	if schedule.NextRunAt == nil {
+		return types.ScheduleRun{}, errors.New("schedule has no next run")
+	}
+
+	var run types.ScheduleRun
+	err := s.runTx(ctx, "run_schedule", func(tx *sqlx.Tx) error {
+		run = types.ScheduleRun{
+			ScheduleID: schedule.ID,
+			RunAt:      *schedule.NextRunAt,
+		}
+
+		var scheduleID types.ScheduleID
+		if err := tx.GetContext(ctx, &scheduleID, queryLockDueSchedule, schedule.ID, run.RunAt); err != nil {
+			if err == sql.ErrNoRows {
+				return types.ErrScheduleRunClaimed
+			}
+			return errors.Wrap(err, "lock schedule")
+		}
+
+		res, err := tx.ExecContext(ctx, queryClaimScheduleRun, run.ScheduleID, run.RunAt)
+		if err != nil {
+			return errors.Wrap(err, "claim schedule run")
+		}
+		claimed, err := res.RowsAffected()
+		if err != nil {
+			return errors.Wrap(err, "claim schedule run result")
+		}
+		if claimed == 0 {
+			return types.ErrScheduleRunClaimed
+		}
+
+		// rejected transfer is rolled back to the savepoint, so its failure is recorded in the same transaction
+		if _, err := tx.ExecContext(ctx, querySavepointScheduleTransfer); err != nil {
+			return errors.Wrap(err, "create savepoint")
+		}
+
+		transferID, err := s.transfer(ctx, tx, schedule.FromWallet, schedule.ToWallet, schedule.Amount)
+		switch {
+		case err == nil:
+			run.TransferID = transferID
+		case transferRejected(err):
+			if _, err := tx.ExecContext(ctx, queryRollbackToSavepointScheduleTransfer); err != nil {
+				return errors.Wrap(err, "rollback to savepoint")
+			}
+			run.Error = errors.Cause(err).Error()
+		default:
+			return errors.Wrap(err, "transfer")
+		}
+
+		if _, err := tx.ExecContext(ctx, queryUpdateScheduleRun, run.ScheduleID, run.RunAt, run.TransferID, run.Error); err != nil {
+			return errors.Wrap(err, "update schedule run")
+		}
+
+		_, err = tx.ExecContext(ctx, queryAdvanceSchedule, run.ScheduleID, next, run.RunAt)
+		return errors.Wrap(err, "advance schedule")
+	})
+	if err != nil {
+		return types.ScheduleRun{}, err
+	}
+
+	return run, nil
+}
+
+// transferRejected reports whether the transfer can't be done because of wallets state, limits or exchange rates,
+// retry of such transfer fails the same way
+func transferRejected(err error) bool {
+	switch errors.Cause(err) {
+	case types.ErrWalletNotFound, types.ErrWalletFrozen, types.ErrWalletClosed, types.ErrUnavailableBalance,
+		types.ErrFXRateNotFound, types.ErrConvertedAmountTooSmall, types.ErrAmountOverflow, types.ErrLimitExceeded:
+		return true
+	}
+	return false
+}
@@ -2,7 +2,7 @@ package storage
 
 import (
 	"context"
-	"fmt"
+	"database/sql"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -11,136 +11,345 @@ import (
 )
 
 type storage struct {
-	conn *sqlx.DB
+	conn                 *sqlx.DB
+	idempotencyRetention time.Duration
+	holdTTL              time.Duration
+	fxSpreadBps          int
+	maxTxRetries         int
 }
 
-func New(conn *sqlx.DB) *storage {
+// New creates storage, fxSpreadBps is the spread in basis points applied to exchange rates of cross-currency transfers,
+// maxTxRetries is the number of retries of transactions aborted by deadlocks or serialization failures
+func New(conn *sqlx.DB, idempotencyRetention, holdTTL time.Duration, fxSpreadBps, maxTxRetries int) *storage {
 	return &storage{
-		conn: conn,
+		conn:                 conn,
+		idempotencyRetention: idempotencyRetention,
+		holdTTL:              holdTTL,
+		fxSpreadBps:          fxSpreadBps,
+		maxTxRetries:         maxTxRetries,
 	}
 }
 
-func (s *storage) CreateWallet(ctx context.Context, wallet types.WalletID) error {
-	_, err := s.conn.ExecContext(ctx, queryInsertWallet, wallet)
+func (s *storage) CreateWallet(ctx context.Context, wallet types.WalletID, currencyCode string) error {
+	_, err := s.conn.ExecContext(ctx, queryInsertWallet, wallet, currencyCode)
 	return errors.Wrap(err, "create wallet query error")
 }
 
-func (s *storage) Deposit(ctx context.Context, wallet types.WalletID, amount int) error {
-	tx, err := s.conn.BeginTxx(ctx, nil)
-	if err != nil {
-		return errors.Wrap(err, "begin transaction")
+func (s *storage) Wallet(ctx context.Context, wallet types.WalletID) (types.Wallet, error) {
+	var w types.Wallet
+	if err := s.conn.GetContext(ctx, &w, querySelectWallet, wallet); err != nil {
+		if err == sql.ErrNoRows {
+			return w, &types.WalletNotFoundError{Field: "wallet"}
+		}
+		return w, errors.Wrap(err, "select wallet")
 	}
 
-	var balance int
-	if err := tx.QueryRowContext(ctx, queryLockWalletForCreate, wallet).Scan(&balance); err != nil {
-		return completeTx(tx, errors.Wrap(err, "lock wallet"))
-	}
+	return w, nil
+}
 
-	if _, err := tx.ExecContext(ctx, queryInsertOperation, wallet, types.OperationTypeDeposit, amount); err != nil {
-		return completeTx(tx, errors.Wrap(err, "create operation"))
-	}
+func (s *storage) Deposit(ctx context.Context, wallet types.WalletID, amount types.Money, idemKey *types.IdempotencyKey) error {
+	return s.runTx(ctx, "deposit", func(tx *sqlx.Tx) error {
+		replayed, _, err := s.acquireIdempotencyKey(ctx, tx, idemKey)
+		if err != nil {
+			return err
+		}
+		if replayed {
+			return nil
+		}
 
-	if _, err := tx.ExecContext(ctx, queryUpdateWallet, balance+amount, wallet); err != nil {
-		return completeTx(tx, errors.Wrap(err, "update balance"))
-	}
+		var (
+			balance        types.Money
+			currencyCode   string
+			status         types.WalletStatus
+			overdraftLimit types.Money
+		)
+		if err := tx.QueryRowContext(ctx, queryLockWalletForCreate, wallet).Scan(&balance, &currencyCode, &status, &overdraftLimit); err != nil {
+			if err == sql.ErrNoRows {
+				return &types.WalletNotFoundError{Field: "wallet"}
+			}
+			return errors.Wrap(err, "lock wallet")
+		}
+
+		if err := status.CheckActive("wallet"); err != nil {
+			return err
+		}
+
+		if _, err := balance.Add(amount); err != nil {
+			return err
+		}
+
+		entryID, err := postEntry(ctx, tx, types.EntryTypeDeposit,
+			types.Posting{AccountID: types.AccountExternalDeposits, Currency: currencyCode, Amount: -amount},
+			types.Posting{AccountID: types.WalletAccount(wallet), Currency: currencyCode, Amount: amount},
+		)
+		if err != nil {
+			return err
+		}
+
+		if _, err := insertOperation(ctx, tx, newOperation{
+			WalletID: wallet,
+			Type:     types.OperationTypeDeposit,
+			Amount:   amount,
+			Currency: currencyCode,
+			EntryID:  entryID,
+		}); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (s *storage) Withdraw(ctx context.Context, wallet types.WalletID, amount types.Money, idemKey *types.IdempotencyKey) error {
+	return s.runTx(ctx, "withdraw", func(tx *sqlx.Tx) error {
+		replayed, _, err := s.acquireIdempotencyKey(ctx, tx, idemKey)
+		if err != nil {
+			return err
+		}
+		if replayed {
+			return nil
+		}
+
+		var (
+			balance        types.Money
+			currencyCode   string
+			status         types.WalletStatus
+			overdraftLimit types.Money
+		)
+		if err := tx.QueryRowContext(ctx, queryLockWalletForCreate, wallet).Scan(&balance, &currencyCode, &status, &overdraftLimit); err != nil {
+			if err == sql.ErrNoRows {
+				return &types.WalletNotFoundError{Field: "wallet"}
+			}
+			return errors.Wrap(err, "lock wallet")
+		}
+
+		if err := status.CheckActive("wallet"); err != nil {
+			return err
+		}
+
+		if err := checkTransferLimits(ctx, tx, wallet, currencyCode, amount); err != nil {
+			return err
+		}
+
+		held, err := heldAmount(ctx, tx, wallet)
+		if err != nil {
+			return err
+		}
+
+		if err := checkDebit(balance, held, overdraftLimit, amount); err != nil {
+			return err
+		}
+
+		entryID, err := postEntry(ctx, tx, types.EntryTypeWithdraw,
+			types.Posting{AccountID: types.WalletAccount(wallet), Currency: currencyCode, Amount: -amount},
+			types.Posting{AccountID: types.AccountExternalWithdrawals, Currency: currencyCode, Amount: amount},
+		)
+		if err != nil {
+			return err
+		}
+
+		if _, err := insertOperation(ctx, tx, newOperation{
+			WalletID: wallet,
+			Type:     types.OperationTypeWithdraw,
+			Amount:   amount,
+			Currency: currencyCode,
+			EntryID:  entryID,
+		}); err != nil {
+			return err
+		}
 
-	return completeTx(tx, nil)
+		return nil
+	})
 }
 
-func (s *storage) Transfer(ctx context.Context, fromWallet, toWallet types.WalletID, amount int) error {
-	tx, err := s.conn.BeginTxx(ctx, nil)
+func (s *storage) Transfer(ctx context.Context, fromWallet, toWallet types.WalletID, amount types.Money, idemKey *types.IdempotencyKey) (types.TransferID, error) {
+	var transferID types.TransferID
+	err := s.runTx(ctx, "transfer", func(tx *sqlx.Tx) error {
+		replayed, result, err := s.acquireIdempotencyKey(ctx, tx, idemKey)
+		if err != nil {
+			return err
+		}
+		if replayed {
+			transferID = types.TransferID(result)
+			return nil
+		}
+
+		if transferID, err = s.transfer(ctx, tx, fromWallet, toWallet, amount); err != nil {
+			return err
+		}
+
+		return saveIdempotencyResult(ctx, tx, idemKey, string(transferID))
+	})
 	if err != nil {
-		return errors.Wrap(err, "begin transaction")
+		return "", err
 	}
 
+	return transferID, nil
+}
+
+// transferWallet is the state of the wallet taking part in transfer
+type transferWallet struct {
+	id             string
+	balance        types.Money
+	currency       string
+	status         types.WalletStatus
+	overdraftLimit types.Money
+}
+
+// lockTransferWallets locks both wallets of the transfer in order of identifiers, so concurrent transfers A->B and B->A
+// don't deadlock, and checks that wallets exist and are active
+func lockTransferWallets(ctx context.Context, tx *sqlx.Tx, fromWallet, toWallet types.WalletID) (transferWallet, transferWallet, error) {
+	var from, to transferWallet
+
 	rows, err := tx.QueryContext(ctx, queryLockWalletsForTransfer, fromWallet, toWallet)
 	if err != nil {
-		return completeTx(tx, errors.Wrap(err, "lock wallets"))
-	}
-
-	var from, to struct {
-		id      string
-		balance int
+		return from, to, errors.Wrap(err, "lock wallets")
 	}
+	defer rows.Close()
 
 	for rows.Next() {
-		var (
-			id      string
-			balance int
-		)
-		if err := rows.Scan(&id, &balance); err != nil {
-			return completeTx(tx, errors.Wrap(err, "scan rows"))
+		var wallet transferWallet
+		if err := rows.Scan(&wallet.id, &wallet.balance, &wallet.currency, &wallet.status, &wallet.overdraftLimit); err != nil {
+			return from, to, errors.Wrap(err, "scan rows")
 		}
 
-		if id == string(fromWallet) {
-			from.id = id
-			from.balance = balance
+		if wallet.id == string(fromWallet) {
+			from = wallet
 		} else {
-			to.id = id
-			to.balance = balance
+			to = wallet
 		}
 	}
 
-	if from.balance-amount < 0 {
-		return completeTx(tx, types.ErrUnavailableBalance)
+	if err := rows.Err(); err != nil {
+		return from, to, errors.Wrap(err, "iterate rows")
 	}
 
-	// add withdraw operation on fromWallet
-	if _, err := tx.ExecContext(ctx, queryInsertOperation, fromWallet, types.OperationTypeWithdraw, amount); err != nil {
-		return completeTx(tx, errors.Wrap(err, "create operation withdraw"))
+	if from.id == "" {
+		return from, to, &types.WalletNotFoundError{Field: "from_wallet"}
 	}
 
-	// add deposit operation on toWallet
-	if _, err := tx.ExecContext(ctx, queryInsertOperation, toWallet, types.OperationTypeDeposit, amount); err != nil {
-		return completeTx(tx, errors.Wrap(err, "create operation deposit"))
+	if to.id == "" {
+		return from, to, &types.WalletNotFoundError{Field: "to_wallet"}
 	}
 
-	// change fromWallet balance
-	if _, err := tx.ExecContext(ctx, queryUpdateWallet, from.balance-amount, fromWallet); err != nil {
-		return completeTx(tx, errors.Wrap(err, "update fromWallet balance"))
+	if err := from.status.CheckActive("from_wallet"); err != nil {
+		return from, to, err
 	}
 
-	// change toWallet balance
-	if _, err := tx.ExecContext(ctx, queryUpdateWallet, to.balance+amount, toWallet); err != nil {
-		return completeTx(tx, errors.Wrap(err, "update toWallet balance"))
+	if err := to.status.CheckActive("to_wallet"); err != nil {
+		return from, to, err
 	}
 
-	return completeTx(tx, nil)
+	return from, to, nil
 }
 
-func (s *storage) Operations(ctx context.Context, wallet types.WalletID, opType types.OperationType, from, to time.Time) ([]types.DBOperation, error) {
-	where := ""
-	args := map[string]interface{}{
-		"wallet_id": wallet,
+// transfer moves amount from one wallet to another in scope of the transaction,
+// amount must be available on fromWallet balance excluding active holds.
+// Amount is in currency of fromWallet, it's converted to currency of toWallet by the effective exchange rate
+// if currencies of wallets differ.
+func (s *storage) transfer(ctx context.Context, tx *sqlx.Tx, fromWallet, toWallet types.WalletID, amount types.Money) (types.TransferID, error) {
+	transferID, err := newUUID()
+	if err != nil {
+		return "", errors.Wrap(err, "generate transfer id")
+	}
+
+	from, to, err := lockTransferWallets(ctx, tx, fromWallet, toWallet)
+	if err != nil {
+		return "", err
+	}
+
+	if err := checkTransferLimits(ctx, tx, fromWallet, from.currency, amount); err != nil {
+		return "", err
 	}
 
-	if opType != "" {
-		where += " AND operation_type = :operation_type"
-		args["operation_type"] = opType
+	held, err := heldAmount(ctx, tx, fromWallet)
+	if err != nil {
+		return "", err
 	}
 
-	if !from.IsZero() {
-		where += " AND created_at >= :from"
-		args["from"] = fmt.Sprintf("%s 00:00:00", from.Format(types.DateLayout))
+	if err := checkDebit(from.balance, held, from.overdraftLimit, amount); err != nil {
+		return "", err
 	}
 
-	if !to.IsZero() {
-		where += " AND created_at <= :to"
-		args["to"] = fmt.Sprintf("%s 23:59:59", to.Format(types.DateLayout))
+	postings := []types.Posting{
+		{AccountID: types.WalletAccount(fromWallet), Currency: from.currency, Amount: -amount},
+		{AccountID: types.WalletAccount(toWallet), Currency: to.currency, Amount: amount},
 	}
 
-	query := removeExtraWhitespaces(fmt.Sprintf(querySelectOperations, where))
-	query, params, err := sqlx.Named(query, args)
+	toAmount := amount
+	var conversion *types.Conversion
+	if from.currency != to.currency {
+		conversion, err = s.convert(ctx, tx, amount, from.currency, to.currency)
+		if err != nil {
+			return "", err
+		}
+		toAmount = conversion.DestinationAmount
+
+		// fx account buys amount in currency of fromWallet and sells converted amount in currency of toWallet
+		postings = []types.Posting{
+			{AccountID: types.WalletAccount(fromWallet), Currency: from.currency, Amount: -amount},
+			{AccountID: types.AccountFX, Currency: from.currency, Amount: amount},
+			{AccountID: types.AccountFX, Currency: to.currency, Amount: -toAmount},
+			{AccountID: types.WalletAccount(toWallet), Currency: to.currency, Amount: toAmount},
+		}
+	}
+
+	if _, err := to.balance.Add(toAmount); err != nil {
+		return "", err
+	}
+
+	// move amount from fromWallet to toWallet, balances of both wallets are changed by postings
+	entryID, err := postEntry(ctx, tx, types.EntryTypeTransfer, postings...)
 	if err != nil {
-		return nil, errors.Wrap(err, "prepare named query")
+		return "", err
 	}
 
-	query = s.conn.Rebind(query)
+	// add withdraw operation on fromWallet
+	if _, err := insertOperation(ctx, tx, newOperation{
+		WalletID:             fromWallet,
+		Type:                 types.OperationTypeWithdraw,
+		Amount:               amount,
+		Currency:             from.currency,
+		TransferID:           transferID,
+		CounterpartyWalletID: toWallet,
+		EntryID:              entryID,
+		Conversion:           conversion,
+	}); err != nil {
+		return "", err
+	}
 
-	var ops []types.DBOperation
-	if err := s.conn.SelectContext(ctx, &ops, query, params...); err != nil {
-		return nil, errors.Wrap(err, "select operations")
+	// add deposit operation on toWallet
+	if _, err := insertOperation(ctx, tx, newOperation{
+		WalletID:             toWallet,
+		Type:                 types.OperationTypeDeposit,
+		Amount:               toAmount,
+		Currency:             to.currency,
+		TransferID:           transferID,
+		CounterpartyWalletID: fromWallet,
+		EntryID:              entryID,
+		Conversion:           conversion,
+	}); err != nil {
+		return "", err
+	}
+
+	return types.TransferID(transferID), nil
+}
+
+// checkDebit returns types.ErrUnavailableBalance if amount can't be debited from balance,
+// held amount isn't available and balance can go below zero down to -overdraftLimit
+func checkDebit(balance, held, overdraftLimit, amount types.Money) error {
+	available, err := balance.Sub(held)
+	if err != nil {
+		return err
 	}
 
-	return ops, nil
+	remaining, err := available.Sub(amount)
+	if err != nil {
+		return err
+	}
+
+	if remaining < -overdraftLimit {
+		return types.ErrUnavailableBalance
+	}
+	return nil
 }
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"math"
+	"testing"
+
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDebit(t *testing.T) {
+	tests := []struct {
+		name                                  string
+		balance, held, overdraftLimit, amount types.Money
+		err                                   error
+	}{
+		{name: "enough balance", balance: 100, amount: 50},
+		{name: "exact balance", balance: 100, amount: 100},
+		{name: "insufficient balance", balance: 100, amount: 101, err: types.ErrUnavailableBalance},
+		{name: "zero overdraft limit on zero balance", amount: 1, err: types.ErrUnavailableBalance},
+		{name: "exact overdraft limit", balance: 100, overdraftLimit: 50, amount: 150},
+		{name: "overdraft limit exceeded", balance: 100, overdraftLimit: 50, amount: 151, err: types.ErrUnavailableBalance},
+		{name: "held funds are not available", balance: 100, held: 30, amount: 71, err: types.ErrUnavailableBalance},
+		{name: "exact available balance with held funds", balance: 100, held: 30, amount: 70},
+		{name: "held funds use overdraft limit", balance: 100, held: 120, overdraftLimit: 50, amount: 30},
+		{name: "held funds exceed overdraft limit", balance: 100, held: 120, overdraftLimit: 50, amount: 31, err: types.ErrUnavailableBalance},
+		{name: "max amount within max overdraft limit", overdraftLimit: math.MaxInt64, amount: math.MaxInt64},
+		{name: "max amount overflows used overdraft", balance: -2, overdraftLimit: math.MaxInt64, amount: math.MaxInt64, err: types.ErrAmountOverflow},
+		{name: "held funds overflow", balance: math.MinInt64 + 1, held: 2, err: types.ErrAmountOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDebit(tt.balance, tt.held, tt.overdraftLimit, tt.amount)
+			assert.Equal(t, tt.err, errors.Cause(err))
+		})
+	}
+}
@@ -1,12 +1,96 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"expvar"
+	"math/rand"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
+const (
+	pqDeadlockDetected     = "40P01"
+	pqSerializationFailure = "40001"
+
+	txRetryBaseDelay = 10 * time.Millisecond
+	txRetryMaxDelay  = 500 * time.Millisecond
+)
+
+// retries of transactions by operation name, published by expvar
+var (
+	txRetries          = expvar.NewMap("storage_tx_retries")
+	txRetriesExhausted = expvar.NewMap("storage_tx_retries_exhausted")
+)
+
+// runTx runs fn in a transaction and completes the transaction by the result of fn.
+// The transaction is retried from the start with jittered exponential backoff up to maxTxRetries times
+// if Postgres aborts it because of a deadlock or a serialization failure, so fn must not have side effects
+// outside of the transaction. Name of the operation is used in logs and metrics.
+func (s *storage) runTx(ctx context.Context, name string, fn func(tx *sqlx.Tx) error) error {
+	for attempt := 0; ; attempt++ {
+		tx, err := s.conn.BeginTxx(ctx, nil)
+		if err != nil {
+			return errors.Wrap(err, "begin transaction")
+		}
+
+		err = completeTx(tx, fn(tx))
+
+		code, retryable := retryableTxError(err)
+		if !retryable {
+			return err
+		}
+
+		if attempt >= s.maxTxRetries {
+			txRetriesExhausted.Add(name, 1)
+			log.WithError(err).WithField("operation", name).Errorf("transaction failed after %d retries", attempt)
+			return err
+		}
+
+		delay := txRetryDelay(attempt)
+		txRetries.Add(name, 1)
+		log.WithFields(log.Fields{
+			"operation": name,
+			"attempt":   attempt + 1,
+			"code":      code,
+			"delay":     delay,
+		}).Warn("retrying transaction")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Wrap(ctx.Err(), "wait for transaction retry")
+		case <-timer.C:
+		}
+	}
+}
+
+// retryableTxError returns Postgres error code if err is caused by a deadlock or a serialization failure
+func retryableTxError(err error) (pq.ErrorCode, bool) {
+	pqErr, ok := errors.Cause(err).(*pq.Error)
+	if !ok {
+		return "", false
+	}
+	return pqErr.Code, pqErr.Code == pqDeadlockDetected || pqErr.Code == pqSerializationFailure
+}
+
+// txRetryDelay returns random delay between the half and the whole of exponentially growing cap,
+// so retries of conflicting transactions spread out
+func txRetryDelay(attempt int) time.Duration {
+	limit := txRetryMaxDelay
+	if attempt < 6 {
+		if d := txRetryBaseDelay << uint(attempt); d < limit {
+			limit = d
+		}
+	}
+	return limit/2 + time.Duration(rand.Int63n(int64(limit/2)+1))
+}
+
 func completeTx(tx *sqlx.Tx, err error) error {
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryableTxError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		code      pq.ErrorCode
+		retryable bool
+	}{
+		{name: "deadlock", err: &pq.Error{Code: "40P01"}, code: "40P01", retryable: true},
+		{name: "serialization failure", err: &pq.Error{Code: "40001"}, code: "40001", retryable: true},
+		{name: "wrapped deadlock", err: errors.Wrap(&pq.Error{Code: "40P01"}, "commit failed"), code: "40P01", retryable: true},
+		{name: "unique violation", err: &pq.Error{Code: "23505"}, code: "23505"},
+		{name: "not postgres error", err: errors.New("some error")},
+		{name: "no error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, retryable := retryableTxError(tt.err)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.retryable, retryable)
+		})
+	}
+}
+
+func TestTxRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		limit   time.Duration
+	}{
+		{attempt: 0, limit: txRetryBaseDelay},
+		{attempt: 1, limit: 2 * txRetryBaseDelay},
+		{attempt: 5, limit: 32 * txRetryBaseDelay},
+		{attempt: 6, limit: txRetryMaxDelay},
+		{attempt: 100, limit: txRetryMaxDelay},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			delay := txRetryDelay(tt.attempt)
+			assert.True(t, delay >= tt.limit/2 && delay <= tt.limit, "attempt %d: delay %s is out of [%s, %s]", tt.attempt, delay, tt.limit/2, tt.limit)
+		}
+	}
+}
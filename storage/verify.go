@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+// Verify recomputes balances of all wallets from operations and ledger postings and compares them with stored ones,
+// also it looks for orphan transfer legs, negative balances and unbalanced journal entries.
+// All checks are run in a single repeatable read transaction, so they see the same snapshot of data.
+func (s *storage) Verify(ctx context.Context) (types.VerificationReport, error) {
+	var report types.VerificationReport
+
+	tx, err := s.conn.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return report, errors.Wrap(err, "begin transaction")
+	}
+
+	if err := tx.SelectContext(ctx, &report.BalanceDrifts, querySelectBalanceDrifts); err != nil {
+		return report, completeTx(tx, errors.Wrap(err, "select balance drifts"))
+	}
+
+	if err := tx.SelectContext(ctx, &report.OrphanTransfers, querySelectOrphanTransfers); err != nil {
+		return report, completeTx(tx, errors.Wrap(err, "select orphan transfers"))
+	}
+
+	if err := tx.SelectContext(ctx, &report.NegativeBalances, querySelectNegativeBalances); err != nil {
+		return report, completeTx(tx, errors.Wrap(err, "select negative balances"))
+	}
+
+	if err := tx.SelectContext(ctx, &report.UnbalancedEntries, querySelectUnbalancedEntries); err != nil {
+		return report, completeTx(tx, errors.Wrap(err, "select unbalanced entries"))
+	}
+
+	return report, completeTx(tx, nil)
+}
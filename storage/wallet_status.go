@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+)
+
+// SetWalletStatus changes status of wallet and saves the change with reason to the status history.
+// Closed wallet can't change its status, wallet can be closed only with zero balance.
+func (s *storage) SetWalletStatus(ctx context.Context, wallet types.WalletID, status types.WalletStatus, reason string) error {
+	return s.runTx(ctx, "set_wallet_status", func(tx *sqlx.Tx) error {
+		var (
+			balance types.Money
+			current types.WalletStatus
+		)
+		if err := tx.QueryRowContext(ctx, queryLockWalletStatus, wallet).Scan(&balance, &current); err != nil {
+			if err == sql.ErrNoRows {
+				return &types.WalletNotFoundError{Field: "wallet"}
+			}
+			return errors.Wrap(err, "lock wallet")
+		}
+
+		if current == types.WalletStatusClosed {
+			return types.ErrWalletClosed
+		}
+		if current == status {
+			return types.ErrWalletStatusUnchanged
+		}
+		if status == types.WalletStatusClosed && balance != 0 {
+			return types.ErrWalletNotEmpty
+		}
+
+		if _, err := tx.ExecContext(ctx, queryUpdateWalletStatus, status, wallet); err != nil {
+			return errors.Wrap(err, "update wallet status")
+		}
+
+		if _, err := tx.ExecContext(ctx, queryInsertWalletStatusChange, wallet, current, status, reason); err != nil {
+			return errors.Wrap(err, "insert wallet status change")
+		}
+
+		return nil
+	})
+}
+
+// WalletStatusHistory fetches status changes of wallet from the oldest to the newest one
+func (s *storage) WalletStatusHistory(ctx context.Context, wallet types.WalletID) ([]types.WalletStatusChange, error) {
+	var changes []types.WalletStatusChange
+	if err := s.conn.SelectContext(ctx, &changes, querySelectWalletStatusHistory, wallet); err != nil {
+		return nil, errors.Wrap(err, "select wallet status history")
+	}
+
+	if len(changes) == 0 {
+		var exists bool
+		if err := s.conn.QueryRowContext(ctx, queryWalletExists, wallet).Scan(&exists); err != nil {
+			return nil, errors.Wrap(err, "check wallet exists")
+		}
+		if !exists {
+			return nil, &types.WalletNotFoundError{Field: "wallet"}
+		}
+	}
+
+	return changes, nil
+}
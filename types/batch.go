@@ -0,0 +1,53 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrBatchNotFound = errors.New("batch not found")
+
+type BatchID string
+
+// Valid checks that identifier is UUID in canonical form
+func (id BatchID) Valid() bool {
+	return isUUID(string(id))
+}
+
+// BatchLeg is a single transfer of the batch from the source wallet of the batch,
+// TransferID is set once the leg is executed
+type BatchLeg struct {
+	ToWallet   WalletID   `db:"to_wallet_id" json:"to_wallet"`
+	Amount     Money      `db:"amount" json:"amount"`
+	TransferID TransferID `db:"transfer_id" json:"transfer_id,omitempty"`
+}
+
+// TransferBatch is a set of transfers from one wallet executed all together or not at all,
+// amounts are in currency of the source wallet
+type TransferBatch struct {
+	ID          BatchID    `db:"id" json:"batch_id"`
+	FromWallet  WalletID   `db:"from_wallet_id" json:"from_wallet"`
+	Currency    string     `db:"currency" json:"currency"`
+	TotalAmount Money      `db:"total_amount" json:"total_amount"`
+	Legs        []BatchLeg `db:"-" json:"legs"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+}
+
+// BatchLegError points to the leg of the batch which failed by its index in the batch
+type BatchLegError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchLegError) Error() string {
+	return fmt.Sprintf("legs[%d]: %s", e.Index, e.Err)
+}
+
+func (e *BatchLegError) Cause() error {
+	return e.Err
+}
+
+func (e *BatchLegError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,24 @@
+package types
+
+import "time"
+
+// FXRate is the price of one major unit of base currency in major units of quote currency,
+// the rate is effective since EffectiveFrom until the next version of the rate of the same pair
+type FXRate struct {
+	ID            int64     `json:"-" db:"id"`
+	BaseCurrency  string    `json:"base_currency" db:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency" db:"quote_currency"`
+	Rate          string    `json:"rate" db:"rate"`
+	EffectiveFrom time.Time `json:"effective_from" db:"effective_from"`
+}
+
+// Conversion describes currency exchange made by cross-currency transfer, it's recorded on both legs of the transfer.
+// Rate is the applied rate with spread and RateID refers to the version of the rate it's based on.
+type Conversion struct {
+	SourceAmount        Money
+	SourceCurrency      string
+	DestinationAmount   Money
+	DestinationCurrency string
+	RateID              int64
+	Rate                string
+}
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrLimitExceeded = errors.New("transfer limit exceeded")
+
+// names of transfer limits
+const (
+	LimitMaxAmount      = "max_amount"
+	LimitMaxDailyAmount = "max_daily_amount"
+	LimitMaxHourlyCount = "max_hourly_count"
+)
+
+// TransferLimits restrict outgoing transfers of the wallet or of all wallets in the currency (default limits),
+// limits of the wallet replace default ones as a whole. Nil limit isn't checked.
+// Amounts are in minor units of wallet currency, daily amount and hourly count are counted over rolling 24 hours and 1 hour.
+type TransferLimits struct {
+	WalletID       WalletID `db:"wallet_id" json:"wallet_id,omitempty"`
+	Currency       string   `db:"currency" json:"currency,omitempty"`
+	MaxAmount      *Money   `db:"max_amount" json:"max_amount"`
+	MaxDailyAmount *Money   `db:"max_daily_amount" json:"max_daily_amount"`
+	MaxHourlyCount *int64   `db:"max_hourly_count" json:"max_hourly_count"`
+}
+
+// LimitExceededError describes violated transfer limit and the allowance which remains under it,
+// its cause is ErrLimitExceeded
+type LimitExceededError struct {
+	Limit string
+	// Remaining is the max amount of a single transfer for LimitMaxAmount,
+	// the rest of daily amount or hourly count for other limits
+	Remaining int64
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s: %s, remaining %d", ErrLimitExceeded, e.Limit, e.Remaining)
+}
+
+func (e *LimitExceededError) Cause() error {
+	return ErrLimitExceeded
+}
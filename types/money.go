@@ -0,0 +1,71 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/justteddy/wallet/currency"
+)
+
+// ErrAmountOverflow is the same error which currency.ParseDecimal returns for decimal amounts out of range
+var ErrAmountOverflow = currency.ErrAmountOutOfRange
+
+// Money is amount in minor units of currency (cents).
+// In JSON it's accepted as a number or as a string with integer number to avoid precision loss in JavaScript clients.
+type Money int64
+
+// AmountOverflowError describes arithmetic operation on amounts which result is out of range,
+// its cause is ErrAmountOverflow
+type AmountOverflowError struct {
+	Op   string
+	X, Y Money
+}
+
+func (e *AmountOverflowError) Error() string {
+	return fmt.Sprintf("%s %s %d and %d", ErrAmountOverflow, e.Op, e.X, e.Y)
+}
+
+func (e *AmountOverflowError) Cause() error {
+	return ErrAmountOverflow
+}
+
+// Add returns m + x or error if the sum is out of range
+func (m Money) Add(x Money) (Money, error) {
+	if (x > 0 && m > math.MaxInt64-x) || (x < 0 && m < math.MinInt64-x) {
+		return 0, &AmountOverflowError{Op: "add", X: m, Y: x}
+	}
+	return m + x, nil
+}
+
+// Sub returns m - x or error if the difference is out of range
+func (m Money) Sub(x Money) (Money, error) {
+	if (x < 0 && m > math.MaxInt64+x) || (x > 0 && m < math.MinInt64+x) {
+		return 0, &AmountOverflowError{Op: "subtract", X: m, Y: x}
+	}
+	return m - x, nil
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 {
+		return errors.New("empty amount")
+	}
+
+	v, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return ErrAmountOverflow
+		}
+		return fmt.Errorf("amount %s must be an integer number of minor units, use decimal_amount for decimal values", data)
+	}
+
+	*m = Money(v)
+	return nil
+}
@@ -0,0 +1,70 @@
+package types_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/justteddy/wallet/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoneyAdd(t *testing.T) {
+	sum, err := types.Money(100).Add(50)
+	require.NoError(t, err)
+	assert.Equal(t, types.Money(150), sum)
+
+	sum, err = types.Money(math.MaxInt64 - 1).Add(1)
+	require.NoError(t, err)
+	assert.Equal(t, types.Money(math.MaxInt64), sum)
+
+	_, err = types.Money(math.MaxInt64).Add(1)
+	assert.Equal(t, types.ErrAmountOverflow, errors.Cause(err))
+
+	_, err = types.Money(math.MinInt64).Add(-1)
+	assert.Equal(t, types.ErrAmountOverflow, errors.Cause(err))
+}
+
+func TestMoneySub(t *testing.T) {
+	diff, err := types.Money(100).Sub(150)
+	require.NoError(t, err)
+	assert.Equal(t, types.Money(-50), diff)
+
+	_, err = types.Money(math.MinInt64).Sub(1)
+	assert.Equal(t, types.ErrAmountOverflow, errors.Cause(err))
+
+	_, err = types.Money(math.MaxInt64).Sub(-1)
+	assert.Equal(t, types.ErrAmountOverflow, errors.Cause(err))
+}
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		data     string
+		expected types.Money
+		err      string
+	}{
+		{data: `100`, expected: 100},
+		{data: `"100"`, expected: 100},
+		{data: `"9007199254740993"`, expected: 9007199254740993},
+		{data: `"9223372036854775807"`, expected: math.MaxInt64},
+		{data: `"9223372036854775808"`, err: "amount is out of range"},
+		{data: `1.5`, err: "amount 1.5 must be an integer number of minor units, use decimal_amount for decimal values"},
+		{data: `"abc"`, err: "amount abc must be an integer number of minor units, use decimal_amount for decimal values"},
+		{data: `""`, err: "empty amount"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.data, func(t *testing.T) {
+			var m types.Money
+			err := json.Unmarshal([]byte(c.data), &m)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, m)
+		})
+	}
+}
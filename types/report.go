@@ -0,0 +1,146 @@
+package types
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/justteddy/wallet/currency"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// OperationsCursor is the position of the last operation of the report page,
+// operations are ordered by creation time and identifier descending
+type OperationsCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// String encodes cursor to the opaque value returned to clients
+func (c OperationsCursor) String() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixMicro(), 10) + ":" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseOperationsCursor decodes cursor encoded by OperationsCursor.String
+func ParseOperationsCursor(s string) (OperationsCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return OperationsCursor{}, ErrInvalidCursor
+	}
+
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 2 {
+		return OperationsCursor{}, ErrInvalidCursor
+	}
+
+	micros, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return OperationsCursor{}, ErrInvalidCursor
+	}
+
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || id <= 0 {
+		return OperationsCursor{}, ErrInvalidCursor
+	}
+
+	return OperationsCursor{CreatedAt: time.UnixMicro(micros).UTC(), ID: id}, nil
+}
+
+// OperationsFilter selects a page of wallet operations created from From inclusive to To exclusive,
+// zero values of filters mean no filter, page starts after Cursor when it's set
+type OperationsFilter struct {
+	Type   OperationType
+	From   time.Time
+	To     time.Time
+	Cursor *OperationsCursor
+	Limit  int
+}
+
+// OperationRows iterates over operations of the page read from storage one by one,
+// Err returns the error which stopped iteration, rows should be closed after use
+type OperationRows interface {
+	Next() bool
+	Operation() DBOperation
+	Err() error
+	Close() error
+}
+
+// OperationsPage is a page of wallet operations with balances of the wallet at the boundaries of the report range,
+// which are the same for all pages of the range
+type OperationsPage struct {
+	Rows OperationRows
+	// NextCursor is nil on the last page
+	NextCursor     *OperationsCursor
+	Currency       string
+	OpeningBalance Money
+	ClosingBalance Money
+}
+
+// ExportIterator iterates over exported operations one by one
+type ExportIterator interface {
+	Next() bool
+	Operation() ExportOperation
+	Err() error
+}
+
+// ExportRows transforms operations of rows to exported ones while they are read,
+// amounts are formatted with opts and times are in loc
+func ExportRows(rows OperationRows, opts currency.Options, loc *time.Location) ExportIterator {
+	return &exportRows{rows: rows, opts: opts, loc: loc}
+}
+
+type exportRows struct {
+	rows OperationRows
+	opts currency.Options
+	loc  *time.Location
+}
+
+func (r *exportRows) Next() bool {
+	return r.rows.Next()
+}
+
+func (r *exportRows) Operation() ExportOperation {
+	return TransformDBToExportOperation(r.rows.Operation(), r.opts, r.loc)
+}
+
+func (r *exportRows) Err() error {
+	return r.rows.Err()
+}
+
+// types of the report rows with balances of the wallet at the boundaries of the report range
+const (
+	ExportRowOpeningBalance = "opening_balance"
+	ExportRowClosingBalance = "closing_balance"
+)
+
+// ExportBalance returns the report row with balance of the wallet at the range boundary formatted in loc,
+// the date is empty for the unbounded range
+func ExportBalance(rowType string, wallet WalletID, balance Money, code string, boundary time.Time, opts currency.Options, loc *time.Location) ExportOperation {
+	var date string
+	if !boundary.IsZero() {
+		date = boundary.In(loc).Format(time.RFC3339Nano)
+	}
+
+	return ExportOperation{
+		WalletID:      string(wallet),
+		OperationType: rowType,
+		Currency:      code,
+		Balance:       currency.FormatWith(int64(balance), code, opts),
+		Date:          date,
+	}
+}
+
+// ExportPage is a page of exported operations, NextCursor is empty on the last page.
+// Operations go from the newest to the oldest, so the closing balance precedes operations of the first page
+// and the opening balance follows operations of the last page.
+type ExportPage struct {
+	Operations     ExportIterator
+	NextCursor     string
+	FirstPage      bool
+	OpeningBalance ExportOperation
+	ClosingBalance ExportOperation
+}
@@ -0,0 +1,30 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/justteddy/wallet/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationsCursor(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		cursor := types.OperationsCursor{
+			CreatedAt: time.Date(2030, 1, 2, 3, 4, 5, 123456000, time.UTC),
+			ID:        42,
+		}
+
+		parsed, err := types.ParseOperationsCursor(cursor.String())
+		require.NoError(t, err)
+		assert.Equal(t, cursor, parsed)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		for _, s := range []string{"", "!!!", "MTIz", "YTox", "MTIzOmE", "MTIzOjA"} {
+			_, err := types.ParseOperationsCursor(s)
+			assert.Equal(t, types.ErrInvalidCursor, err, s)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrScheduleNotFound  = errors.New("schedule not found")
+	ErrScheduleNotActive = errors.New("schedule is already completed or cancelled")
+	// ErrScheduleRunClaimed is returned for the run which is executed by another process
+	ErrScheduleRunClaimed = errors.New("schedule run is already claimed")
+)
+
+type ScheduleID string
+
+// Valid checks that identifier is UUID in canonical form
+func (id ScheduleID) Valid() bool {
+	return isUUID(string(id))
+}
+
+// ScheduleRecurrence is the period between transfers of the schedule
+type ScheduleRecurrence string
+
+const (
+	ScheduleRecurrenceOnce    ScheduleRecurrence = "once"
+	ScheduleRecurrenceDaily   ScheduleRecurrence = "daily"
+	ScheduleRecurrenceWeekly  ScheduleRecurrence = "weekly"
+	ScheduleRecurrenceMonthly ScheduleRecurrence = "monthly"
+)
+
+var AllScheduleRecurrences = map[ScheduleRecurrence]struct{}{
+	ScheduleRecurrenceOnce:    {},
+	ScheduleRecurrenceDaily:   {},
+	ScheduleRecurrenceWeekly:  {},
+	ScheduleRecurrenceMonthly: {},
+}
+
+// Next returns the first occurrence of the schedule started at start which is after `after`,
+// false is returned if there is no such occurrence. Occurrences keep the wall clock time and the day of start
+// in its location, so start must be in the timezone of the schedule.
+// Monthly schedules started on the day missing in a month (ex. 31st) occur on the last day of that month.
+func (r ScheduleRecurrence) Next(start, after time.Time) (time.Time, bool) {
+	if start.After(after) {
+		return start, true
+	}
+
+	var n int
+	switch r {
+	case ScheduleRecurrenceDaily:
+		n = int(after.Sub(start) / (24 * time.Hour))
+	case ScheduleRecurrenceWeekly:
+		n = int(after.Sub(start) / (7 * 24 * time.Hour))
+	case ScheduleRecurrenceMonthly:
+		n = (after.Year()-start.Year())*12 + int(after.Month()-start.Month()) - 1
+	default:
+		return time.Time{}, false
+	}
+	if n < 0 {
+		n = 0
+	}
+
+	for {
+		if next := r.occurrence(start, n); next.After(after) {
+			return next, true
+		}
+		n++
+	}
+}
+
+// occurrence returns n-th occurrence of the schedule started at start, the start is 0-th occurrence
+func (r ScheduleRecurrence) occurrence(start time.Time, n int) time.Time {
+	switch r {
+	case ScheduleRecurrenceDaily:
+		return start.AddDate(0, 0, n)
+	case ScheduleRecurrenceWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case ScheduleRecurrenceMonthly:
+		year, month, day := start.Date()
+		first := time.Date(year, month+time.Month(n), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		if last := first.AddDate(0, 1, -1).Day(); day > last {
+			day = last
+		}
+		return first.AddDate(0, 0, day-1)
+	}
+	return start
+}
+
+// LoadScheduleLocation returns location of the schedule timezone, it's IANA name or UTC offset like "+02:00"
+func LoadScheduleLocation(timezone string) (*time.Location, error) {
+	if t, err := time.Parse("-07:00", timezone); err == nil {
+		_, offset := t.Zone()
+		return time.FixedZone(timezone, offset), nil
+	}
+
+	// Local is the timezone of the server, it's not a user's one
+	if timezone == "Local" {
+		return nil, errors.New("unsupported timezone")
+	}
+	return time.LoadLocation(timezone)
+}
+
+type ScheduleStatus string
+
+const (
+	ScheduleStatusActive    ScheduleStatus = "active"
+	ScheduleStatusCompleted ScheduleStatus = "completed"
+	ScheduleStatusCancelled ScheduleStatus = "cancelled"
+)
+
+// Schedule transfers amount from one wallet to another at start and then every period of recurrence
+// in its timezone, NextRunAt is nil once the schedule is completed or cancelled
+type Schedule struct {
+	ID         ScheduleID         `db:"id" json:"schedule_id"`
+	FromWallet WalletID           `db:"from_wallet_id" json:"from_wallet"`
+	ToWallet   WalletID           `db:"to_wallet_id" json:"to_wallet"`
+	Amount     Money              `db:"amount" json:"amount"`
+	Recurrence ScheduleRecurrence `db:"recurrence" json:"recurrence"`
+	StartAt    time.Time          `db:"start_at" json:"start_at"`
+	Timezone   string             `db:"timezone" json:"timezone"`
+	NextRunAt  *time.Time         `db:"next_run_at" json:"next_run_at"`
+	Status     ScheduleStatus     `db:"status" json:"status"`
+	CreatedAt  time.Time          `db:"created_at" json:"created_at"`
+}
+
+// ScheduleRun is the execution of the schedule occurrence planned at RunAt,
+// it has either identifier of the transfer or the error of failed execution
+type ScheduleRun struct {
+	ScheduleID ScheduleID `db:"schedule_id" json:"-"`
+	RunAt      time.Time  `db:"run_at" json:"run_at"`
+	TransferID TransferID `db:"transfer_id" json:"transfer_id,omitempty"`
+	Error      string     `db:"error" json:"error,omitempty"`
+	ExecutedAt time.Time  `db:"executed_at" json:"executed_at"`
+}
@@ -0,0 +1,146 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+	// IANA timezones are available without tzdata in the system
+	_ "time/tzdata"
+
+	"github.com/justteddy/wallet/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleRecurrenceNext(t *testing.T) {
+	date := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		name       string
+		recurrence types.ScheduleRecurrence
+		start      time.Time
+		after      time.Time
+		next       time.Time
+		ok         bool
+	}{
+		{
+			name:       "once before start",
+			recurrence: types.ScheduleRecurrenceOnce,
+			start:      date(2022, time.March, 1, 10),
+			after:      date(2022, time.February, 1, 10),
+			next:       date(2022, time.March, 1, 10),
+			ok:         true,
+		},
+		{
+			name:       "once at start",
+			recurrence: types.ScheduleRecurrenceOnce,
+			start:      date(2022, time.March, 1, 10),
+			after:      date(2022, time.March, 1, 10),
+		},
+		{
+			name:       "daily at occurrence",
+			recurrence: types.ScheduleRecurrenceDaily,
+			start:      date(2022, time.March, 1, 10),
+			after:      date(2022, time.March, 5, 10),
+			next:       date(2022, time.March, 6, 10),
+			ok:         true,
+		},
+		{
+			name:       "daily between occurrences",
+			recurrence: types.ScheduleRecurrenceDaily,
+			start:      date(2022, time.March, 1, 10),
+			after:      date(2022, time.March, 5, 9),
+			next:       date(2022, time.March, 5, 10),
+			ok:         true,
+		},
+		{
+			name:       "weekly",
+			recurrence: types.ScheduleRecurrenceWeekly,
+			start:      date(2022, time.March, 1, 10),
+			after:      date(2022, time.March, 9, 0),
+			next:       date(2022, time.March, 15, 10),
+			ok:         true,
+		},
+		{
+			name:       "monthly",
+			recurrence: types.ScheduleRecurrenceMonthly,
+			start:      date(2022, time.January, 15, 10),
+			after:      date(2022, time.March, 15, 10),
+			next:       date(2022, time.April, 15, 10),
+			ok:         true,
+		},
+		{
+			name:       "monthly on the last day of short month",
+			recurrence: types.ScheduleRecurrenceMonthly,
+			start:      date(2022, time.January, 31, 10),
+			after:      date(2022, time.January, 31, 10),
+			next:       date(2022, time.February, 28, 10),
+			ok:         true,
+		},
+		{
+			name:       "monthly returns to the start day after short month",
+			recurrence: types.ScheduleRecurrenceMonthly,
+			start:      date(2022, time.January, 31, 10),
+			after:      date(2022, time.February, 28, 10),
+			next:       date(2022, time.March, 31, 10),
+			ok:         true,
+		},
+		{
+			name:       "monthly over the year",
+			recurrence: types.ScheduleRecurrenceMonthly,
+			start:      date(2022, time.November, 30, 10),
+			after:      date(2023, time.January, 30, 11),
+			next:       date(2023, time.February, 28, 10),
+			ok:         true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			next, ok := c.recurrence.Next(c.start, c.after)
+			assert.Equal(t, c.ok, ok)
+			assert.Equal(t, c.next, next)
+		})
+	}
+
+	t.Run("monthly in timezone with positive offset", func(t *testing.T) {
+		loc, err := types.LoadScheduleLocation("+03:00")
+		require.NoError(t, err)
+
+		// the start is on January 30th in UTC, the occurrence in February is on its last day in the schedule timezone
+		start := time.Date(2022, time.January, 31, 1, 0, 0, 0, loc)
+		next, ok := types.ScheduleRecurrenceMonthly.Next(start, start)
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2022, time.February, 28, 1, 0, 0, 0, loc), next)
+		assert.Equal(t, time.Date(2022, time.February, 27, 22, 0, 0, 0, time.UTC), next.UTC())
+	})
+
+	t.Run("daily over daylight saving time change", func(t *testing.T) {
+		loc, err := types.LoadScheduleLocation("Europe/Berlin")
+		require.NoError(t, err)
+
+		start := time.Date(2022, time.March, 26, 9, 0, 0, 0, loc)
+		next, ok := types.ScheduleRecurrenceDaily.Next(start, start)
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2022, time.March, 27, 9, 0, 0, 0, loc), next)
+		assert.Equal(t, 23*time.Hour, next.Sub(start))
+	})
+}
+
+func TestLoadScheduleLocation(t *testing.T) {
+	loc, err := types.LoadScheduleLocation("-05:30")
+	require.NoError(t, err)
+	_, offset := time.Date(2022, time.March, 1, 0, 0, 0, 0, loc).Zone()
+	assert.Equal(t, -(5*3600 + 30*60), offset)
+
+	loc, err = types.LoadScheduleLocation("Europe/Berlin")
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", loc.String())
+
+	_, err = types.LoadScheduleLocation("Local")
+	assert.Error(t, err)
+
+	_, err = types.LoadScheduleLocation("Mars/Olympus")
+	assert.Error(t, err)
+}
@@ -2,16 +2,94 @@ package types
 
 import (
 	"errors"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/justteddy/wallet/currency"
 )
 
 const DateLayout = "2006-01-02"
 
-var ErrUnavailableBalance = errors.New("insufficient funds in the account")
+var (
+	ErrUnavailableBalance   = errors.New("insufficient funds in the account")
+	ErrOverdraftLimitTooLow = errors.New("overdraft limit is less than used credit")
+	ErrIdempotencyKeyReused = errors.New("idempotency key is already used for another request")
+	ErrWalletNotFound       = errors.New("wallet not found")
+	ErrUnbalancedEntry      = errors.New("journal entry postings don't sum to zero")
+
+	ErrFXRateNotFound          = errors.New("exchange rate for currencies of wallets is not found")
+	ErrConvertedAmountTooSmall = errors.New("amount is too small to be converted")
+	ErrFXRateConflict          = errors.New("another rate is already saved for the currency pair and effective time")
+
+	ErrOperationNotFound        = errors.New("operation not found")
+	ErrOperationNotReversible   = errors.New("operation can't be reversed")
+	ErrOperationAlreadyReversed = errors.New("operation is already fully reversed")
+	ErrInvalidReversalAmount    = errors.New("reversal amount exceeds not reversed amount of operation")
+
+	ErrHoldNotFound         = errors.New("hold not found")
+	ErrHoldNotActive        = errors.New("hold is already captured or voided")
+	ErrHoldExpired          = errors.New("hold is expired")
+	ErrInvalidCaptureAmount = errors.New("capture amount exceeds held amount")
+)
 
 type WalletID string
 
+// WalletNotFoundError points to the missing wallet of the operation by the name of request field,
+// its cause is ErrWalletNotFound
+type WalletNotFoundError struct {
+	Field string
+}
+
+func (e *WalletNotFoundError) Error() string {
+	return e.Field + " not found"
+}
+
+func (e *WalletNotFoundError) Cause() error {
+	return ErrWalletNotFound
+}
+
+var uuidRegex = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// isUUID checks that s is UUID in canonical lowercase form generated by the storage
+func isUUID(s string) bool {
+	return uuidRegex.MatchString(s)
+}
+
+type TransferID string
+
+type HoldID string
+
+// Valid checks that identifier is UUID in canonical form
+func (id HoldID) Valid() bool {
+	return isUUID(string(id))
+}
+
+type HoldStatus string
+
+const (
+	HoldStatusActive   HoldStatus = "active"
+	HoldStatusCaptured HoldStatus = "captured"
+	HoldStatusVoided   HoldStatus = "voided"
+)
+
+// Hold reserves amount of wallet balance in favor of another wallet until it's captured, voided or expired
+type Hold struct {
+	ID         HoldID     `db:"id"`
+	WalletID   WalletID   `db:"wallet_id"`
+	ToWalletID WalletID   `db:"to_wallet_id"`
+	Amount     Money      `db:"amount"`
+	Status     HoldStatus `db:"status"`
+	Expired    bool       `db:"expired"`
+}
+
+// IdempotencyKey identifies client request which must be applied only once.
+// RequestHash is used to detect reuse of the same key with another request payload.
+type IdempotencyKey struct {
+	Key         string
+	RequestHash string
+}
+
 type ExportFormat string
 
 const (
@@ -24,6 +102,9 @@ type OperationType string
 const (
 	OperationTypeDeposit  OperationType = "deposit"
 	OperationTypeWithdraw OperationType = "withdraw"
+	// OperationTypeReversal compensates deposit, withdraw or transfer leg fully or partially,
+	// its amount is signed: positive amount credits the wallet and negative one debits it
+	OperationTypeReversal OperationType = "reversal"
 )
 
 var (
@@ -35,34 +116,165 @@ var (
 	AllOperationTypes = map[OperationType]struct{}{
 		OperationTypeDeposit:  {},
 		OperationTypeWithdraw: {},
+		OperationTypeReversal: {},
 	}
 )
 
+type Wallet struct {
+	ID              WalletID     `db:"id"`
+	Currency        string       `db:"currency"`
+	Status          WalletStatus `db:"status"`
+	Balance         Money        `db:"balance"`
+	OverdraftLimit  Money        `db:"overdraft_limit"`
+	HeldAmount      Money        `db:"held_amount"`
+	CreatedAt       time.Time    `db:"created_at"`
+	OperationsCount int          `db:"operations_count"`
+	LastOperationAt *time.Time   `db:"last_operation_at"`
+}
+
+// AvailableBalance returns part of ledger balance which isn't held, it's negative if overdraft is used
+func (w Wallet) AvailableBalance() (Money, error) {
+	return w.Balance.Sub(w.HeldAmount)
+}
+
+// UsedCredit returns part of overdraft limit which is used by negative available balance
+func (w Wallet) UsedCredit() (Money, error) {
+	available, err := w.AvailableBalance()
+	if err != nil || available >= 0 {
+		return 0, err
+	}
+	return Money(0).Sub(available)
+}
+
+// RemainingCredit returns part of overdraft limit which can still be used
+func (w Wallet) RemainingCredit() (Money, error) {
+	used, err := w.UsedCredit()
+	if err != nil {
+		return 0, err
+	}
+	return w.OverdraftLimit.Sub(used)
+}
+
+// AccountID identifies ledger account, every wallet has own account and money comes into or goes out
+// of the system through system accounts
+type AccountID string
+
+const (
+	AccountExternalDeposits    AccountID = "system:external_deposits"
+	AccountExternalWithdrawals AccountID = "system:external_withdrawals"
+	// AccountFX buys source currency and sells destination currency of cross-currency transfers
+	AccountFX AccountID = "system:fx"
+
+	systemAccountPrefix = "system:"
+)
+
+// WalletAccount returns ledger account of the wallet
+func WalletAccount(wallet WalletID) AccountID {
+	return AccountID(wallet)
+}
+
+// IsSystem reports whether account is a system one, system accounts don't have wallets
+func (a AccountID) IsSystem() bool {
+	return strings.HasPrefix(string(a), systemAccountPrefix)
+}
+
+type EntryType string
+
+const (
+	EntryTypeDeposit  EntryType = "deposit"
+	EntryTypeWithdraw EntryType = "withdraw"
+	EntryTypeTransfer EntryType = "transfer"
+	EntryTypeReversal EntryType = "reversal"
+)
+
+// Posting changes balance of the account by signed amount in the currency,
+// postings of one journal entry sum to zero in every currency
+type Posting struct {
+	AccountID AccountID
+	Currency  string
+	Amount    Money
+}
+
+// OperationID is the public identifier of the operation, random UUID
+type OperationID string
+
+// Valid checks that identifier is UUID in canonical form
+func (id OperationID) Valid() bool {
+	return isUUID(string(id))
+}
+
 type DBOperation struct {
-	WalletID      WalletID      `db:"wallet_id"`
-	OperationType OperationType `db:"operation_type"`
-	Amount        int           `db:"amount"`
-	CreatedAt     string        `db:"created_at"`
+	// ID is the internal sequential identifier, it orders operations created at the same time
+	ID                   int64         `db:"id"`
+	PublicID             OperationID   `db:"public_id"`
+	WalletID             WalletID      `db:"wallet_id"`
+	OperationType        OperationType `db:"operation_type"`
+	Amount               Money         `db:"amount"`
+	Currency             string        `db:"currency"`
+	TransferID           TransferID    `db:"transfer_id"`
+	CounterpartyWalletID WalletID      `db:"counterparty_wallet_id"`
+	ReversalOf           OperationID   `db:"reversal_of"`
+	SourceAmount         Money         `db:"source_amount"`
+	SourceCurrency       string        `db:"source_currency"`
+	DestinationAmount    Money         `db:"destination_amount"`
+	DestinationCurrency  string        `db:"destination_currency"`
+	FXRate               string        `db:"fx_rate"`
+	UsedCredit           Money         `db:"used_credit"`
+	OverdraftLimit       Money         `db:"overdraft_limit"`
+	// Balance is the balance of the wallet after the operation
+	Balance   Money     `db:"balance_after"`
+	CreatedAt time.Time `db:"created_at"`
 }
 
 type ExportOperation struct {
-	WalletID      string `json:"wallet_id"`
-	OperationType string `json:"operation_type"`
-	Amount        string `json:"amount"`
-	Date          string `json:"date"`
-}
-
-// TransformDBToExportOperation transforms DBOperation to ExportOperation
-func TransformDBToExportOperation(ops []DBOperation) []ExportOperation {
-	expOps := make([]ExportOperation, 0, len(ops))
-	for _, op := range ops {
-		expOps = append(expOps, ExportOperation{
-			WalletID:      string(op.WalletID),
-			OperationType: string(op.OperationType),
-			Amount:        currency.Format(op.Amount),
-			Date:          op.CreatedAt,
-		})
+	ID                   string `json:"id"`
+	WalletID             string `json:"wallet_id"`
+	OperationType        string `json:"operation_type"`
+	Amount               string `json:"amount"`
+	Currency             string `json:"currency"`
+	TransferID           string `json:"transfer_id"`
+	CounterpartyWalletID string `json:"counterparty_wallet_id"`
+	ReversalOf           string `json:"reversal_of"`
+	SourceAmount         string `json:"source_amount"`
+	DestinationAmount    string `json:"destination_amount"`
+	FXRate               string `json:"fx_rate"`
+	UsedCredit           string `json:"used_credit"`
+	RemainingCredit      string `json:"remaining_credit"`
+	Balance              string `json:"balance"`
+	Date                 string `json:"date"`
+}
+
+// TransformDBToExportOperation transforms DBOperation to ExportOperation, amounts are formatted with opts,
+// time of the operation is formatted as RFC 3339 timestamp in loc
+func TransformDBToExportOperation(op DBOperation, opts currency.Options, loc *time.Location) ExportOperation {
+	var sourceAmount, destinationAmount string
+	if op.FXRate != "" {
+		sourceAmount = currency.FormatWith(int64(op.SourceAmount), op.SourceCurrency, opts)
+		destinationAmount = currency.FormatWith(int64(op.DestinationAmount), op.DestinationCurrency, opts)
 	}
 
-	return expOps
+	// credit columns are filled only for wallets with overdraft
+	var usedCredit, remainingCredit string
+	if op.OverdraftLimit != 0 || op.UsedCredit != 0 {
+		usedCredit = currency.FormatWith(int64(op.UsedCredit), op.Currency, opts)
+		remainingCredit = currency.FormatWith(int64(op.OverdraftLimit-op.UsedCredit), op.Currency, opts)
+	}
+
+	return ExportOperation{
+		ID:                   string(op.PublicID),
+		WalletID:             string(op.WalletID),
+		OperationType:        string(op.OperationType),
+		Amount:               currency.FormatWith(int64(op.Amount), op.Currency, opts),
+		Currency:             op.Currency,
+		TransferID:           string(op.TransferID),
+		CounterpartyWalletID: string(op.CounterpartyWalletID),
+		ReversalOf:           string(op.ReversalOf),
+		SourceAmount:         sourceAmount,
+		DestinationAmount:    destinationAmount,
+		FXRate:               op.FXRate,
+		UsedCredit:           usedCredit,
+		RemainingCredit:      remainingCredit,
+		Balance:              currency.FormatWith(int64(op.Balance), op.Currency, opts),
+		Date:                 op.CreatedAt.In(loc).Format(time.RFC3339Nano),
+	}
 }
@@ -0,0 +1,45 @@
+package types
+
+// BalanceDrift describes wallet which balance differs from the balance recomputed from operations or ledger postings
+type BalanceDrift struct {
+	WalletID          WalletID `db:"wallet_id"`
+	Balance           Money    `db:"balance"`
+	OperationsBalance Money    `db:"operations_balance"`
+	LedgerBalance     Money    `db:"ledger_balance"`
+}
+
+// OrphanTransfer describes transfer which doesn't consist of exactly one withdraw and one deposit operation
+type OrphanTransfer struct {
+	TransferID TransferID `db:"transfer_id"`
+	Legs       int        `db:"legs"`
+}
+
+// NegativeBalance describes wallet which balance is below its overdraft limit
+type NegativeBalance struct {
+	WalletID       WalletID `db:"wallet_id"`
+	Balance        Money    `db:"balance"`
+	OverdraftLimit Money    `db:"overdraft_limit"`
+}
+
+// UnbalancedEntry describes journal entry which postings in the currency don't sum to zero
+type UnbalancedEntry struct {
+	EntryID  int64  `db:"entry_id"`
+	Currency string `db:"currency"`
+	Sum      Money  `db:"sum"`
+}
+
+// VerificationReport contains all inconsistencies found by ledger integrity verification
+type VerificationReport struct {
+	BalanceDrifts     []BalanceDrift
+	OrphanTransfers   []OrphanTransfer
+	NegativeBalances  []NegativeBalance
+	UnbalancedEntries []UnbalancedEntry
+}
+
+// OK reports whether no inconsistencies were found
+func (r VerificationReport) OK() bool {
+	return len(r.BalanceDrifts) == 0 &&
+		len(r.OrphanTransfers) == 0 &&
+		len(r.NegativeBalances) == 0 &&
+		len(r.UnbalancedEntries) == 0
+}
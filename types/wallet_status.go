@@ -0,0 +1,65 @@
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrWalletFrozen          = errors.New("wallet is frozen")
+	ErrWalletClosed          = errors.New("wallet is closed")
+	ErrWalletNotEmpty        = errors.New("wallet balance must be zero to close it")
+	ErrWalletStatusUnchanged = errors.New("wallet already has this status")
+)
+
+// WalletStatus is the lifecycle status of wallet, only active wallets take part in operations.
+// Frozen wallet can be activated again, closed wallet can't.
+type WalletStatus string
+
+const (
+	WalletStatusActive WalletStatus = "active"
+	WalletStatusFrozen WalletStatus = "frozen"
+	WalletStatusClosed WalletStatus = "closed"
+)
+
+var AllWalletStatuses = map[WalletStatus]struct{}{
+	WalletStatusActive: {},
+	WalletStatusFrozen: {},
+	WalletStatusClosed: {},
+}
+
+// WalletStatusError points to the wallet of the operation which isn't active by the name of request field,
+// its cause is ErrWalletFrozen or ErrWalletClosed
+type WalletStatusError struct {
+	Field  string
+	Status WalletStatus
+}
+
+func (e *WalletStatusError) Error() string {
+	return e.Field + " is " + string(e.Status)
+}
+
+func (e *WalletStatusError) Cause() error {
+	if e.Status == WalletStatusClosed {
+		return ErrWalletClosed
+	}
+	return ErrWalletFrozen
+}
+
+// CheckActive returns WalletStatusError if wallet with the status can't take part in operations,
+// field is the name of request field with the wallet
+func (s WalletStatus) CheckActive(field string) error {
+	if s == WalletStatusActive {
+		return nil
+	}
+	return &WalletStatusError{Field: field, Status: s}
+}
+
+// WalletStatusChange is the audit record of wallet status change
+type WalletStatusChange struct {
+	WalletID   WalletID     `db:"wallet_id" json:"-"`
+	FromStatus WalletStatus `db:"from_status" json:"from_status"`
+	ToStatus   WalletStatus `db:"to_status" json:"to_status"`
+	Reason     string       `db:"reason" json:"reason"`
+	CreatedAt  time.Time    `db:"created_at" json:"created_at"`
+}
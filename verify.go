@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+
+	"github.com/justteddy/wallet/types"
+	log "github.com/sirupsen/logrus"
+)
+
+const verifyCommand = "verify"
+
+type ledgerVerifier interface {
+	Verify(ctx context.Context) (types.VerificationReport, error)
+}
+
+// runVerify checks ledger integrity, logs all found inconsistencies and returns exit code of the command:
+// 0 - ledger is consistent, 1 - inconsistencies are found, 2 - verification failed
+func runVerify(ctx context.Context, verifier ledgerVerifier) int {
+	report, err := verifier.Verify(ctx)
+	if err != nil {
+		log.WithError(err).Error("ledger verification failed")
+		return 2
+	}
+
+	for _, drift := range report.BalanceDrifts {
+		log.WithFields(log.Fields{
+			"wallet_id":          drift.WalletID,
+			"balance":            drift.Balance,
+			"operations_balance": drift.OperationsBalance,
+			"ledger_balance":     drift.LedgerBalance,
+		}).Error("wallet balance drift")
+	}
+
+	for _, transfer := range report.OrphanTransfers {
+		log.WithFields(log.Fields{
+			"transfer_id": transfer.TransferID,
+			"legs":        transfer.Legs,
+		}).Error("orphan transfer legs")
+	}
+
+	for _, negative := range report.NegativeBalances {
+		log.WithFields(log.Fields{
+			"wallet_id":       negative.WalletID,
+			"balance":         negative.Balance,
+			"overdraft_limit": negative.OverdraftLimit,
+		}).Error("wallet balance is below overdraft limit")
+	}
+
+	for _, entry := range report.UnbalancedEntries {
+		log.WithFields(log.Fields{
+			"entry_id": entry.EntryID,
+			"currency": entry.Currency,
+			"sum":      entry.Sum,
+		}).Error("unbalanced journal entry")
+	}
+
+	if !report.OK() {
+		log.Error("ledger verification found inconsistencies")
+		return 1
+	}
+
+	log.Info("ledger is consistent")
+	return 0
+}